@@ -6,106 +6,96 @@ import (
 	"math"
 	"sort"
 	"strconv"
-
-	"github.com/jakubkosno/pmlb"
 )
 
-func main() {
-	datasets := [...]string{"magic", "confidence", "analcatdata_bankruptcy", "new_thyroid", "analcatdata_cyyoung9302", "analcatdata_boxing1", "balance_scale", "monk2",
-		"lupus", "biomed", "postoperative_patient_data", "cleve", "iris", "labor", "tae", "prnn_fglass", "lymphography", "analcatdata_boxing2",
-		"analcatdata_creditscore", "haberman", "cleveland_nominal", "analcatdata_germangss", "analcatdata_lawsuit", "breast", "prnn_crabs",
-		"analcatdata_japansolvent", "irish", "glass2", "analcatdata_fraud", "breast_cancer", "car", "glass", "analcatdata_aids", "appendicitis", "dermatology",
-		"heart_c", "schizo", "wine_recognition", "confidence", "lupus", "solar_flare_1", "cars"}
-	for i := range datasets {
-		fmt.Println(datasets[i])
-		x, y, err := pmlb.FetchXYData(datasets[i])
-		var x_train, x_test [][]string
-		var y_train, y_test []string
-		for j := range y {
-			if j%4 == 0 {
-				x_test = append(x_test, x[j])
-				y_test = append(y_test, y[j])
-				if j == 0 {
-					x_train = append(x_train, x[j])
-					y_train = append(y_train, y[j])
-				}
-			} else {
-				x_train = append(x_train, x[j])
-				y_train = append(y_train, y[j])
-			}
-		}
-		//		fmt.Println(y_train)
-		//		fmt.Println(x_train)
-		if err != nil {
-			fmt.Println(err)
-		}
-		asonn := BuildNewAsonn(x_train, y_train)
-		asonn.countNodes()
-		asonn.PredictMultiLayer(x_test, y_test)
+type Asonn struct {
+	Nodes       []*Node
+	Calibration *Calibration
+	Config      *Config
+	// Schema holds the column types the model reads its inputs with, nil in
+	// models saved before it was added.
+	Schema Schema
+	index  *graphIndex
+	// samples keeps Value and Object nodes that single-layer training drops
+	// from Nodes, so the model can still learn.
+	samples []*Node
+	// Warn receives problems with prediction input that don't stop
+	// prediction: an *UnknownColumnError for ignored columns and, from
+	// Predict and PredictMultiLayer, which return no error, a
+	// *MissingColumnError. They are logged when Warn is nil. Warn is not
+	// saved with the model.
+	Warn func(error)
+}
+
+// Deprecated: use Train with WithStrategy(SingleLayer). BuildAsonn panics on invalid input.
+func BuildAsonn(x [][]string, y []string) Asonn {
+	asonn, err := Train(x, y, WithStrategy(SingleLayer))
+	if err != nil {
+		panic(err)
 	}
+	return *asonn
 }
 
-type Asonn struct {
-	Nodes []*Node
+// Deprecated: use Train with WithStrategy(MultiLayer). BuildNewAsonn panics on invalid input.
+func BuildNewAsonn(x [][]string, y []string) Asonn {
+	asonn, err := Train(x, y, WithStrategy(MultiLayer))
+	if err != nil {
+		panic(err)
+	}
+	return *asonn
 }
 
-func BuildAsonn(x [][]string, y []string) Asonn {
-	asonn := Asonn{}
-	for _, value := range x[0] {
-		newNode := NewNode(value, Feature)
-		asonn.Nodes = append(asonn.Nodes, &newNode)
+func (asonn *Asonn) addObjects(x [][]string, y []string) ([]*Node, error) {
+	labels := make([]interface{}, len(x)-1)
+	for i := 1; i < len(x); i++ {
+		if y[i] != "" {
+			labels[i-1] = y[i]
+		}
 	}
-	var classNodes []*Node
-	for i, row := range x {
-		if i == 0 || y[i] == "" {
-			continue // Feature names in first row, skip data with no class
+	return asonn.addLabelledObjects(x, labels)
+}
+
+// addLabelledObjects parses rows of x, whose first row holds feature names,
+// and adds them with labels[i-1] for row i like addObjectNodes.
+func (asonn *Asonn) addLabelledObjects(x [][]string, labels []interface{}) ([]*Node, error) {
+	rows := make([][]interface{}, len(x)-1)
+	for i := 1; i < len(x); i++ {
+		if labels[i-1] == nil {
+			continue // Skip data with no class
 		}
-		objectNode := NewNode("O"+strconv.Itoa(i), Object)
-		for j, strValue := range row {
-			value := convertToCorrectType(strValue)
-			newNode, reused := tryToReuseNode(value, asonn.Nodes, j)
-			addConnection(newNode, &objectNode, 1)
-			if !reused {
-				addConnection(newNode, asonn.Nodes[j], 1)
-				asonn.Nodes = append(asonn.Nodes, newNode)
+		rows[i-1] = make([]interface{}, len(x[i]))
+		for j, strValue := range x[i] {
+			if asonn.Schema[j].skips(strValue) {
+				continue
 			}
-		}
-		value := y[i]
-		classNode, reused := tryToReuseClassNode(value, classNodes)
-		addConnection(classNode, &objectNode, 1)
-		if !reused {
-			asonn.Nodes = append(asonn.Nodes, &objectNode)
-			classNodes = append(classNodes, classNode)
-		}
-		asonn.Nodes = append(asonn.Nodes, &objectNode)
-	}
-	asonn.Nodes = append(asonn.Nodes, classNodes...)
-	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type == Feature {
-			asonn.Nodes[i].sortConnections()
+			value, err := asonn.Schema[j].parse(strValue)
+			if err != nil {
+				return nil, fmt.Errorf("%w in row %d", err, i)
+			}
+			rows[i-1][j] = value
 		}
 	}
-	asonn.addAsimAndAdefConnections()
-	asonn.addCombinations()
-	asonn.updateRangeToCombinationConnectionWeights()
-	asonn.removeValueAndObjectNodes()
-	return asonn
+	return asonn.addObjectNodes(x[0], rows, labels), nil
 }
 
-func BuildNewAsonn(x [][]string, y []string) Asonn {
-	asonn := Asonn{}
-	for _, value := range x[0] {
+// addObjectNodes adds Feature nodes named by header and an Object node "O"+i
+// for row i-1 of rows, connected to Value nodes of its values and to the Class
+// node of its label. Rows with a nil label and nil values are skipped.
+func (asonn *Asonn) addObjectNodes(header []string, rows [][]interface{}, labels []interface{}) []*Node {
+	for _, value := range header {
 		newNode := NewNode(value, Feature)
 		asonn.Nodes = append(asonn.Nodes, &newNode)
 	}
 	var classNodes []*Node
-	for i, row := range x {
-		if i == 0 || y[i] == "" {
-			continue // Feature names in first row, skip data with no class
+	for i, row := range rows {
+		if labels[i] == nil {
+			continue
 		}
-		objectNode := NewNode("O"+strconv.Itoa(i), Object)
-		for j, strValue := range row {
-			value := convertToCorrectType(strValue)
+		objectNode := NewNode("O"+strconv.Itoa(i+1), Object)
+		for j, value := range row {
+			if value == nil {
+				continue
+			}
 			newNode, reused := tryToReuseNode(value, asonn.Nodes, j)
 			addConnection(newNode, &objectNode, 1)
 			if !reused {
@@ -113,8 +103,7 @@ func BuildNewAsonn(x [][]string, y []string) Asonn {
 				asonn.Nodes = append(asonn.Nodes, newNode)
 			}
 		}
-		value := y[i]
-		classNode, reused := tryToReuseClassNode(value, classNodes)
+		classNode, reused := tryToReuseClassNode(labels[i], classNodes)
 		addConnection(classNode, &objectNode, 1)
 		if !reused {
 			asonn.Nodes = append(asonn.Nodes, &objectNode)
@@ -128,233 +117,317 @@ func BuildNewAsonn(x [][]string, y []string) Asonn {
 			asonn.Nodes[i].sortConnections()
 		}
 	}
-	asonn.addAsimAndAdefConnections()
-	asonn.addCombinationLayers(classNodes)
-	return asonn
+	asonn.Reindex()
+	return classNodes
 }
 
-func (asonn *Asonn) addCombinationLayers(classNodes []*Node) {
-	combinationNodes := asonn.addCombinationLayer(classNodes)
-	stop := 0
-	for stop < 1 {
-		combinationNodes = asonn.addCombinationSublayer(classNodes, combinationNodes)
-		if len(combinationNodes) == 0 {
-			stop = 1
+func (asonn *Asonn) addCombinationLayers(classNodes []*Node) error {
+	combinationNodes, err := asonn.addCombinationLayer(classNodes)
+	if err != nil {
+		return err
+	}
+	return asonn.addSublayers(classNodes, combinationNodes)
+}
+
+// addSublayers adds the sub-combinations of combinationNodes layer by layer.
+func (asonn *Asonn) addSublayers(classNodes []*Node, combinationNodes []*Node) error {
+	var err error
+	for len(combinationNodes) > 0 {
+		combinationNodes, err = asonn.addCombinationSublayer(classNodes, combinationNodes)
+		if err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
-func (asonn *Asonn) addCombinationLayer(classNodes []*Node) (combinationNodes []*Node) {
+func (asonn *Asonn) addCombinationLayer(classNodes []*Node) ([]*Node, error) {
 	var newCombinations []*Node
 	for i := range classNodes {
-		combinationNode := NewNode("C"+strconv.Itoa(i), Combination)
-		addConnection(&combinationNode, classNodes[i], 1)
-		var newRanges []*Node
-		initialized := false
-		for j := range classNodes[i].Connections {
-			if classNodes[i].Connections[j].Node.Type == Object {
-				if !initialized {
-					initialized = true
-					for k := range classNodes[i].Connections[j].Node.Connections {
-						if classNodes[i].Connections[j].Node.Connections[k].Node.Type == Value {
-							var valRange []interface{}
-							valRange = append(valRange, classNodes[i].Connections[j].Node.Connections[k].Node.Value)
-							rangeNode := NewNode(valRange, Range)
-							addConnection(&combinationNode, &rangeNode, 1)
-							addConnection(&rangeNode, classNodes[i].Connections[j].Node.Connections[k].Node, 1)
-							featureNode, _ := getFeatureConnection(classNodes[i].Connections[j].Node.Connections[k].Node)
-							addConnection(&rangeNode, featureNode, 1)
-							newRanges = append(newRanges, &rangeNode)
-						}
-					}
-				} else {
-					for k := range classNodes[i].Connections[j].Node.Connections {
-						if classNodes[i].Connections[j].Node.Connections[k].Node.Type == Value {
-							for l := range newRanges {
-								rangeFeature, _ := getFeatureConnection(newRanges[l])
-								nodeFeature, _ := getFeatureConnection(classNodes[i].Connections[j].Node.Connections[k].Node)
-								if rangeFeature.Value == nodeFeature.Value {
-									newRanges[l].Value = append(newRanges[l].Value.([]interface{}), classNodes[i].Connections[j].Node.Connections[k].Node.Value)
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-		for j := range newRanges {
-			reduceRange(newRanges[j])
+		combinationNode, newRanges, _, err := newClassCombination(classNodes, i, nil)
+		if err != nil {
+			return nil, err
 		}
-		asonn.Nodes = append(asonn.Nodes, newRanges...)
-		newCombinations = append(newCombinations, &combinationNode)
-		newRanges = nil
+		asonn.addNodes(newRanges...)
+		newCombinations = append(newCombinations, combinationNode)
 	}
-	asonn.Nodes = append(asonn.Nodes, newCombinations...)
-	return newCombinations
+	asonn.addNodes(newCombinations...)
+	return newCombinations, nil
 }
 
-func (asonn *Asonn) addCombinationSublayer(classNodes []*Node, bigCombinationNodes []*Node) (combinationNodes []*Node) {
+func (asonn *Asonn) addCombinationSublayer(classNodes []*Node, bigCombinationNodes []*Node) ([]*Node, error) {
 	var newCombinations []*Node
 	for h := range bigCombinationNodes {
 		for i := range classNodes {
-			if getClassOfObject(bigCombinationNodes[h]) == classNodes[i].Value {
+			if getClassOfObject(bigCombinationNodes[h]) == classLabel(classNodes[i]) {
 				continue
 			}
-			combinationNode := NewNode("C"+strconv.Itoa(i), Combination)
-			addConnection(&combinationNode, classNodes[i], 1)
-			var newRanges []*Node
-			initialized := false
-			for j := range classNodes[i].Connections {
-				if classNodes[i].Connections[j].Node.Type == Object && classNodes[i].Connections[j].Node.isWithinCombination(bigCombinationNodes[h]) {
-					if !initialized {
-						initialized = true
-						for k := range classNodes[i].Connections[j].Node.Connections {
-							if classNodes[i].Connections[j].Node.Connections[k].Node.Type == Value {
-								var valRange []interface{}
-								valRange = append(valRange, classNodes[i].Connections[j].Node.Connections[k].Node.Value)
-								rangeNode := NewNode(valRange, Range)
-								addConnection(&combinationNode, &rangeNode, 1)
-								addConnection(&rangeNode, classNodes[i].Connections[j].Node.Connections[k].Node, 1)
-								featureNode, _ := getFeatureConnection(classNodes[i].Connections[j].Node.Connections[k].Node)
-								addConnection(&rangeNode, featureNode, 1)
-								newRanges = append(newRanges, &rangeNode)
-							}
-						}
-					} else {
-						for k := range classNodes[i].Connections[j].Node.Connections {
-							if classNodes[i].Connections[j].Node.Connections[k].Node.Type == Value {
-								for l := range newRanges {
-									rangeFeature, _ := getFeatureConnection(newRanges[l])
-									nodeFeature, _ := getFeatureConnection(classNodes[i].Connections[j].Node.Connections[k].Node)
-									if rangeFeature.Value == nodeFeature.Value {
-										newRanges[l].Value = append(newRanges[l].Value.([]interface{}), classNodes[i].Connections[j].Node.Connections[k].Node.Value)
-									}
-								}
-							}
-						}
+			combinationNode, newRanges, initialized, err := newClassCombination(classNodes, i, bigCombinationNodes[h])
+			if err != nil {
+				return nil, err
+			}
+			if initialized {
+				asonn.addNodes(newRanges...)
+				addOneWayConnection(bigCombinationNodes[h], combinationNode)
+				newCombinations = append(newCombinations, combinationNode)
+			}
+		}
+	}
+	asonn.addNodes(newCombinations...)
+	return newCombinations, nil
+}
+
+// newClassCombination returns a combination of classNodes[i] with ranges
+// spanning its objects within parent, or all of them when parent is nil. It
+// reports false and stays unconnected when there are no such objects.
+func newClassCombination(classNodes []*Node, i int, parent *Node) (*Node, []*Node, bool, error) {
+	combinationNode := NewNode("C"+strconv.Itoa(i), Combination)
+	addConnection(&combinationNode, classNodes[i], 1)
+	newRanges, initialized, err := addObjectRanges(&combinationNode, classNodes[i], parent)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if !initialized {
+		removeConnection(&combinationNode, classNodes[i])
+	}
+	return &combinationNode, newRanges, initialized, nil
+}
+
+// newObjectRange connects combinationNode to a new range holding valueNode.
+func newObjectRange(combinationNode *Node, valueNode *Node) (*Node, error) {
+	rangeNode := NewNode([]interface{}{valueNode.Value}, Range)
+	addConnection(combinationNode, &rangeNode, 1)
+	addConnection(&rangeNode, valueNode, 1)
+	featureNode, err := getFeatureConnection(valueNode)
+	if err != nil {
+		return nil, err
+	}
+	addConnection(&rangeNode, featureNode, 1)
+	return &rangeNode, nil
+}
+
+// addObjectRanges connects combinationNode to ranges spanning every object of
+// classNode, limited to objects within parent when parent is not nil.
+func addObjectRanges(combinationNode *Node, classNode *Node, parent *Node) ([]*Node, bool, error) {
+	var newRanges []*Node
+	initialized := false
+	for j := range classNode.Connections {
+		objectNode := classNode.Connections[j].Node
+		if objectNode.Type != Object {
+			continue
+		}
+		if parent != nil {
+			within, err := objectNode.isWithinCombination(parent)
+			if err != nil {
+				return nil, false, err
+			}
+			if !within {
+				continue
+			}
+		}
+		if !initialized {
+			initialized = true
+			for k := range objectNode.Connections {
+				if objectNode.Connections[k].Node.Type == Value {
+					rangeNode, err := newObjectRange(combinationNode, objectNode.Connections[k].Node)
+					if err != nil {
+						return nil, false, err
 					}
+					newRanges = append(newRanges, rangeNode)
 				}
 			}
-			if initialized {
-				for j := range newRanges {
-					reduceRange(newRanges[j])
+		} else {
+			for k := range objectNode.Connections {
+				if objectNode.Connections[k].Node.Type == Value {
+					nodeFeature, err := getFeatureConnection(objectNode.Connections[k].Node)
+					if err != nil {
+						return nil, false, err
+					}
+					found := false
+					for l := range newRanges {
+						rangeFeature, err := getFeatureConnection(newRanges[l])
+						if err != nil {
+							return nil, false, err
+						}
+						if rangeFeature == nodeFeature {
+							newRanges[l].Value = append(newRanges[l].Value.([]interface{}), objectNode.Connections[k].Node.Value)
+							found = true
+						}
+					}
+					if !found {
+						// Objects seen so far had the value missing.
+						rangeNode, err := newObjectRange(combinationNode, objectNode.Connections[k].Node)
+						if err != nil {
+							return nil, false, err
+						}
+						newRanges = append(newRanges, rangeNode)
+					}
 				}
-				asonn.Nodes = append(asonn.Nodes, newRanges...)
-				addOneWayConnection(bigCombinationNodes[h], &combinationNode)
-				newCombinations = append(newCombinations, &combinationNode)
-				newRanges = nil
 			}
 		}
 	}
-	asonn.Nodes = append(asonn.Nodes, newCombinations...)
-	return newCombinations
+	for j := range newRanges {
+		if err := reduceRange(newRanges[j]); err != nil {
+			return nil, false, err
+		}
+	}
+	return newRanges, initialized, nil
 }
 
-func (objectNode *Node) isWithinCombination(combinationNode *Node) bool {
+func (objectNode *Node) isWithinCombination(combinationNode *Node) (bool, error) {
 	for i := range combinationNode.Connections {
 		if combinationNode.Connections[i].Node.Type == Range {
-			rangeFeature, _ := getFeatureConnection(combinationNode.Connections[i].Node)
+			rangeFeature, err := getFeatureConnection(combinationNode.Connections[i].Node)
+			if err != nil {
+				return false, err
+			}
 			for j := range objectNode.Connections {
 				if objectNode.Connections[j].Node.Type == Value {
-					nodeFeature, _ := getFeatureConnection(objectNode.Connections[j].Node)
-					if rangeFeature == nodeFeature {
-						minVal := combinationNode.Connections[i].Node.Value.([2]interface{})[0]
-						maxVal := combinationNode.Connections[i].Node.Value.([2]interface{})[1]
-						val, ok := objectNode.Connections[j].Node.Value.(float64)
-						if !ok {
-							valInt, _ := objectNode.Connections[j].Node.Value.(int32)
-							val = float64(valInt)
+					nodeFeature, err := getFeatureConnection(objectNode.Connections[j].Node)
+					if err != nil {
+						return false, err
+					}
+					if rangeFeature != nodeFeature {
+						continue
+					}
+					if categories, ok := combinationNode.Connections[i].Node.Value.(Categories); ok {
+						category, _ := objectNode.Connections[j].Node.Value.(string)
+						if !categories.contains(category) {
+							return false, nil
 						}
-						if val < minVal.(float64) || val > maxVal.(float64) {
-							return false
+					} else {
+						minVal, _ := convertToFloat64(combinationNode.Connections[i].Node.Value.([2]interface{})[0])
+						maxVal, _ := convertToFloat64(combinationNode.Connections[i].Node.Value.([2]interface{})[1])
+						val, err := convertToFloat64(objectNode.Connections[j].Node.Value)
+						if err != nil {
+							return false, ErrNonNumericRange
+						}
+						if val < minVal || val > maxVal {
+							return false, nil
 						}
 					}
 				}
 			}
 		}
 	}
-	return true
+	return true, nil
 }
 
 func (asonn *Asonn) PredictMultiLayer(test [][]string, y_test []string) {
+	if err := asonn.matchColumns(test[0]); err != nil {
+		asonn.warn(err)
+	}
+	test = asonn.fillMissing(test)
 	features := test[0]
 	values := test[1:]
 	y_test = y_test[1:]
 	all := 0.0
 	correct := 0.0
 	for i := range values {
-		asonn.resetActivations()
 		all += 1
 		result := asonn.classify(values[i], features)
 		if result == y_test[i] {
 			correct += 1
 		}
-		//		fmt.Println(y_test[i])
-		//		fmt.Println(result)
-		//		fmt.Println()
-		//		for j := range asonn.Nodes {
-		//			if asonn.Nodes[j].Type == Combination {
-		//				fmt.Print(getClassOfObject(asonn.Nodes[j]))
-		//				fmt.Print(" ")
-		//				fmt.Print(asonn.Nodes[j].Activation)
-		//				fmt.Println()
-		//			}
-		//		}
 	}
 	fmt.Println(correct / all)
 }
 
+// activations holds node activations of a single inference call, so the
+// trained graph is only read during prediction and can be shared by goroutines.
+type activations map[*Node]float64
+
 func (asonn *Asonn) classify(test []string, features []string) string {
-	for i := range test {
-		for j := range asonn.Nodes {
-			if asonn.Nodes[j].Type == Combination {
-				for k := range asonn.Nodes[j].Connections {
-					if asonn.Nodes[j].Connections[k].Node.Type == Range {
-						rangeFeature, _ := getFeatureConnection(asonn.Nodes[j].Connections[k].Node)
-						if rangeFeature.Value == features[i] {
-							asonn.Nodes[j].Connections[k].Node.Activation = asonn.Nodes[j].Connections[k].Node.getActivation(test[i])
-							asonn.Nodes[j].Activation += asonn.Nodes[j].Connections[k].Node.Activation * asonn.Nodes[j].Connections[k].Weight
-						}
+	_, winner := asonn.activateCombinations(test, features)
+	if winner == nil {
+		return ""
+	}
+	return getClassOfObject(winner)
+}
+
+func (asonn *Asonn) activateCombinations(test []string, features []string) (activations, *Node) {
+	graph := asonn.graph()
+	return asonn.activateInputs(features, func(i int, feature *Node) (string, float64, bool) {
+		if column, ok := graph.columns[feature]; ok && column.skips(test[i]) {
+			return "", 0, false
+		}
+		return test[i], graph.input(feature, test[i]), true
+	})
+}
+
+// inputReader returns the cell and the numeric value of column i of a row
+// for feature, and false when the value is missing.
+type inputReader func(i int, feature *Node) (string, float64, bool)
+
+func (asonn *Asonn) activateInputs(features []string, input inputReader) (activations, *Node) {
+	graph := asonn.graph()
+	combinations := graph.nodes(Combination)
+	state := make(activations)
+	skipped := 0
+	for i := range features {
+		for _, feature := range graph.features[features[i]] {
+			cell, value, present := input(i, feature)
+			if !present {
+				skipped++
+				continue
+			}
+			if graph.categorical[feature] {
+				for _, rangeNode := range graph.categoryRanges[feature][cell] {
+					if owner, ok := graph.rangeOwners[rangeNode]; ok {
+						state[rangeNode] = 1.0
+						state[owner.combination] += owner.combination.Connections[owner.connection].Weight
 					}
 				}
+				continue
+			}
+			membership := graph.memberships[feature]
+			for _, rangeNode := range graph.rangesAround(feature, value) {
+				owner, ok := graph.rangeOwners[rangeNode]
+				if !ok {
+					continue
+				}
+				state[rangeNode] = rangeNode.activationAt(value, membership, graph.config.ActivationScale)
+				state[owner.combination] += state[rangeNode] * owner.combination.Connections[owner.connection].Weight
 			}
 		}
 	}
-	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type == Combination {
-			for j := range asonn.Nodes[i].Connections {
-				if asonn.Nodes[i].Connections[j].Node.Type == Combination {
-					asonn.Nodes[i].Activation -= asonn.Nodes[i].Connections[j].Node.Activation * math.Pow(asonn.Nodes[i].Connections[j].Node.Activation/asonn.getFeaturesNumber(), 5)
-				}
+	graph.normalize(state, skipped)
+	featuresNumber := graph.featuresNumber()
+	inhibitionExponent := graph.config.InhibitionExponent
+	for _, combination := range combinations {
+		for _, connection := range combination.Connections {
+			if connection.Node.Type == Combination {
+				state[combination] -= state[connection.Node] * math.Pow(state[connection.Node]/featuresNumber, inhibitionExponent)
 			}
 		}
 	}
 	maxActivation := -1.0
-	result := ""
-	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type == Combination && asonn.Nodes[i].Activation > float64(maxActivation) {
-			result = getClassOfObject(asonn.Nodes[i])
-			maxActivation = asonn.Nodes[i].Activation
+	var result *Node
+	for _, combination := range combinations {
+		if state[combination] > maxActivation {
+			result = combination
+			maxActivation = state[combination]
 		}
 	}
-	return result
+	return state, result
 }
 
 func (asonn *Asonn) Predict(test [][]string) []float64 {
+	if err := asonn.matchColumns(test[0]); err != nil {
+		asonn.warn(err)
+	}
+	test = asonn.fillMissing(test)
 	var results []float64
 	features := test[0]
 	values := test[1:]
 	for i := range values {
-		asonn.resetActivations()
-		asonn.activate(values[i], features)
+		state := asonn.activate(values[i], features)
 		maxActivation := -1.0
 		result := 0.0
-		for j := range asonn.Nodes {
-			if asonn.Nodes[j].Type == Combination {
-				if asonn.Nodes[j].Activation > maxActivation {
-					maxActivation = asonn.Nodes[j].Activation
-					result = asonn.Nodes[j].Activation
-				}
+		for _, combination := range asonn.graph().nodes(Combination) {
+			if state[combination] > maxActivation {
+				maxActivation = state[combination]
+				result = state[combination]
 			}
 		}
 		results = append(results, result)
@@ -362,19 +435,32 @@ func (asonn *Asonn) Predict(test [][]string) []float64 {
 	return results
 }
 
-func (asonn *Asonn) activate(test []string, features []string) {
+func (asonn *Asonn) activate(test []string, features []string) activations {
+	graph := asonn.graph()
+	state := make(activations)
 	var activated []*Node
+	// Combinations are told apart by name like they always were here, so of
+	// combinations sharing a name only the first activated one is summed.
+	seen := make(map[interface{}]bool)
+	skipped := 0
 	for i := range test {
-		newActivated := asonn.activateFeature(convertToCorrectType(test[i]), features[i])
-		for j := range newActivated {
-			if !contains(activated, newActivated[j]) {
-				activated = append(activated, newActivated[j])
+		for _, feature := range graph.features[features[i]] {
+			if column, ok := graph.columns[feature]; ok && column.skips(test[i]) {
+				skipped++
+			}
+		}
+		for _, node := range graph.activateFeature(test[i], features[i], state) {
+			if !seen[node.Value] {
+				seen[node.Value] = true
+				activated = append(activated, node)
 			}
 		}
 	}
 	for i := range activated {
-		activated[i].activateCombination()
+		activated[i].activateCombination(state)
 	}
+	graph.normalize(state, skipped)
+	return state
 }
 
 func (asonn *Asonn) countNodes() {
@@ -388,18 +474,28 @@ func (asonn *Asonn) countNodes() {
 	fmt.Println(val)
 }
 
-func (asonn *Asonn) activateFeature(value interface{}, feature string) []*Node {
+func (graph *graphIndex) activateFeature(cell string, feature string, state activations) []*Node {
 	var activated []*Node
-	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type == Feature && asonn.Nodes[i].Value == feature {
-			for j := range asonn.Nodes[i].Connections {
-				if asonn.Nodes[i].Connections[j].Node.Type == Range {
-					activatedNode := asonn.Nodes[i].Connections[j].Node.activate(value)
-					if activatedNode != nil && !contains(activated, activatedNode) {
-						activated = append(activated, activatedNode)
-					}
+	for _, featureNode := range graph.features[feature] {
+		if column, ok := graph.columns[featureNode]; ok && column.skips(cell) {
+			continue
+		}
+		if graph.categorical[featureNode] {
+			for _, rangeNode := range graph.categoryRanges[featureNode][cell] {
+				activatedNode := rangeNode.activateCategory(state)
+				if activatedNode != nil && !containsNode(activated, activatedNode) {
+					activated = append(activated, activatedNode)
 				}
 			}
+			continue
+		}
+		value := graph.input(featureNode, cell)
+		membership := graph.memberships[featureNode]
+		for _, rangeNode := range graph.rangesAround(featureNode, value) {
+			activatedNode := rangeNode.activate(value, membership, graph.config.ActivationScale, state)
+			if activatedNode != nil && !containsNode(activated, activatedNode) {
+				activated = append(activated, activatedNode)
+			}
 		}
 	}
 	return activated
@@ -408,93 +504,119 @@ func (asonn *Asonn) activateFeature(value interface{}, feature string) []*Node {
 func (asonn *Asonn) addAsimAndAdefConnections() {
 	for i := range asonn.Nodes {
 		if asonn.Nodes[i].Type == Feature {
-			// Check if value is numeric
-			maxVal, maxOk := asonn.Nodes[i].Connections[len(asonn.Nodes[i].Connections)-1].Node.Value.(float64)
-			minVal, minOk := asonn.Nodes[i].Connections[0].Node.Value.(float64)
-			if minOk && maxOk {
-				valRange := maxVal - minVal
-				for j := range asonn.Nodes[i].Connections {
-					if j == len(asonn.Nodes[i].Connections)-1 {
-						break
-					}
-					weight := (valRange - (asonn.Nodes[i].Connections[j+1].Node.Value.(float64) - asonn.Nodes[i].Connections[j].Node.Value.(float64))) / valRange
-					addConnection(asonn.Nodes[i].Connections[j].Node, asonn.Nodes[i].Connections[j+1].Node, weight)
-				}
+			var values []*Node
+			for j := range asonn.Nodes[i].Connections {
+				values = append(values, asonn.Nodes[i].Connections[j].Node)
 			}
+			linkValues(values)
 		}
 		if asonn.Nodes[i].Type == Object {
-			denominator := 0.0
-			for j := range asonn.Nodes[i].Connections {
-				if asonn.Nodes[i].Connections[j].Node.Type == Value {
-					denominator += float64(countObjectConnectionsFromClass(asonn.Nodes[i].Connections[j].Node, getClassOfObject(asonn.Nodes[i]))) / float64(countObjectConnections(asonn.Nodes[i].Connections[j].Node))
-				}
-			}
-			for j := range asonn.Nodes[i].Connections {
-				if asonn.Nodes[i].Connections[j].Node.Type == Value {
-					weight := (float64(countObjectConnectionsFromClass(asonn.Nodes[i].Connections[j].Node, getClassOfObject(asonn.Nodes[i]))) / float64(countObjectConnections(asonn.Nodes[i].Connections[j].Node))) / denominator
-					asonn.Nodes[i].Connections[j].Weight = weight
-				}
+			weighObject(asonn.Nodes[i])
+		}
+	}
+}
+
+// linkValues connects neighbouring values of a feature, sorted in descending
+// order, with ASIM weights. Non numeric values are not connected.
+func linkValues(values []*Node) {
+	maxVal, maxOk := values[len(values)-1].Value.(float64)
+	minVal, minOk := values[0].Value.(float64)
+	if minOk && maxOk {
+		valRange := maxVal - minVal
+		for j := range values {
+			if j == len(values)-1 {
+				break
 			}
+			weight := (valRange - (values[j+1].Value.(float64) - values[j].Value.(float64))) / valRange
+			addConnection(values[j], values[j+1], weight)
+		}
+	}
+}
+
+// weighObject sets ADEF weights of connections from object to its values.
+func weighObject(object *Node) {
+	denominator := 0.0
+	for j := range object.Connections {
+		if object.Connections[j].Node.Type == Value {
+			denominator += float64(countObjectConnectionsFromClass(object.Connections[j].Node, getClassOfObject(object))) / float64(countObjectConnections(object.Connections[j].Node))
+		}
+	}
+	for j := range object.Connections {
+		if object.Connections[j].Node.Type == Value {
+			weight := (float64(countObjectConnectionsFromClass(object.Connections[j].Node, getClassOfObject(object))) / float64(countObjectConnections(object.Connections[j].Node))) / denominator
+			object.Connections[j].Weight = weight
 		}
 	}
 }
 
-func (asonn *Asonn) addCombinations() {
+func (asonn *Asonn) addCombinations() error {
 	i := 0
 	for asonn.countNotRepresentedObjects() > 0 {
-		fmt.Println(asonn.countNotRepresentedObjects())
 		combinationSeed := asonn.getMostOutCorrelatedObjectNode()
-		combinationNode := NewNode("C"+strconv.Itoa(i), Combination)
-		addConnection(combinationSeed, &combinationNode, 1)
-		for j := range combinationSeed.Connections {
-			if combinationSeed.Connections[j].Node.Type == Value {
-				var valRange []interface{}
-				valRange = append(valRange, combinationSeed.Connections[j].Node.Value)
-				rangeNode := NewNode(valRange, Range)
-				addConnection(&combinationNode, &rangeNode, 1)
-				addConnection(&rangeNode, combinationSeed.Connections[j].Node, 1)
-				featureNode, _ := getFeatureConnection(combinationSeed.Connections[j].Node)
-				addConnection(&rangeNode, featureNode, 1)
-				asonn.Nodes = append(asonn.Nodes, &rangeNode)
-			} else if combinationSeed.Connections[j].Node.Type == Class {
-				addConnection(&combinationNode, combinationSeed.Connections[j].Node, 1)
-			}
+		if _, err := asonn.seedCombination(combinationSeed, "C"+strconv.Itoa(i)); err != nil {
+			return err
 		}
-		asonn.Nodes = append(asonn.Nodes, &combinationNode)
-		asonn.expandCombination(&combinationNode)
 		i++
 	}
+	return nil
+}
+
+// seedCombination adds a combination around combinationSeed and expands it.
+func (asonn *Asonn) seedCombination(combinationSeed *Node, name string) (*Node, error) {
+	combinationNode := NewNode(name, Combination)
+	addConnection(combinationSeed, &combinationNode, 1)
+	for j := range combinationSeed.Connections {
+		if combinationSeed.Connections[j].Node.Type == Value {
+			var valRange []interface{}
+			valRange = append(valRange, combinationSeed.Connections[j].Node.Value)
+			rangeNode := NewNode(valRange, Range)
+			addConnection(&combinationNode, &rangeNode, 1)
+			addConnection(&rangeNode, combinationSeed.Connections[j].Node, 1)
+			featureNode, err := getFeatureConnection(combinationSeed.Connections[j].Node)
+			if err != nil {
+				return nil, err
+			}
+			addConnection(&rangeNode, featureNode, 1)
+			asonn.addNodes(&rangeNode)
+		} else if combinationSeed.Connections[j].Node.Type == Class {
+			addConnection(&combinationNode, combinationSeed.Connections[j].Node, 1)
+		}
+	}
+	asonn.addNodes(&combinationNode)
+	if err := asonn.expandCombination(&combinationNode); err != nil {
+		return nil, err
+	}
+	return &combinationNode, nil
 }
 
 func (asonn Asonn) countNotRepresentedObjects() int {
 	counter := 0
-	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type == Object {
-			isConnected := false
-			for j := range asonn.Nodes[i].Connections {
-				if asonn.Nodes[i].Connections[j].Node.Type == Combination {
-					isConnected = true
-					break
-				}
-			}
-			if !isConnected {
-				counter++
+	for _, object := range asonn.graph().nodes(Object) {
+		isConnected := false
+		for j := range object.Connections {
+			if object.Connections[j].Node.Type == Combination {
+				isConnected = true
+				break
 			}
 		}
+		if !isConnected {
+			counter++
+		}
 	}
 	return counter
 }
 
 func (asonn Asonn) getMostOutCorrelatedObjectNode() *Node {
-	maxIndex := asonn.getFirstNotRepresentedObjectIndex()
-	length := asonn.Nodes[maxIndex].countValueConnections() + 1
+	objects := asonn.graph().nodes(Object)
+	result := objects[0]
+	length := result.countValueConnections() + 1
 	var maxOutCorrelations []int
 	maxOutCorrelations = append(maxOutCorrelations, make([]int, length)...)
 	changed := false
-	for i := range asonn.Nodes {
+	for _, object := range objects {
 		represented := false
-		for j := range asonn.Nodes[i].Connections {
-			if asonn.Nodes[i].Connections[j].Node.Type == Combination {
+		for j := range object.Connections {
+			if object.Connections[j].Node.Type == Combination {
 				represented = true
 				break
 			}
@@ -502,18 +624,16 @@ func (asonn Asonn) getMostOutCorrelatedObjectNode() *Node {
 		if represented {
 			continue
 		}
-		if asonn.Nodes[i].Type == Object {
-			outCorrelations, err := asonn.calculateObjectNodeOutCorrelation(asonn.Nodes[i])
-			if err == nil {
-				maxOutCorrelations, changed = getBiggerCorrelation(maxOutCorrelations, outCorrelations)
-				if changed {
-					maxIndex = i
-				}
+		outCorrelations, err := asonn.calculateObjectNodeOutCorrelation(object)
+		if err == nil {
+			maxOutCorrelations, changed = getBiggerCorrelation(maxOutCorrelations, outCorrelations)
+			if changed {
+				result = object
 			}
-			changed = false
 		}
+		changed = false
 	}
-	return asonn.Nodes[maxIndex]
+	return result
 }
 
 func (asonn Asonn) calculateObjectNodeOutCorrelation(node *Node) ([]int, error) {
@@ -523,14 +643,21 @@ func (asonn Asonn) calculateObjectNodeOutCorrelation(node *Node) ([]int, error)
 	length := node.countValueConnections() + 1
 	var outCorrelations []int
 	outCorrelations = append(outCorrelations, make([]int, length)...)
-	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type == Object {
-			commmonFeatures, err := countCommonFeatures(node, asonn.Nodes[i])
-			if err == nil {
-				outCorrelations[length-commmonFeatures-1]++
+	// Value nodes are shared by objects with the same value of a feature, so
+	// common features are counted by walking the values of node once.
+	commonFeatures := make(map[*Node]int)
+	for i := range node.Connections {
+		if node.Connections[i].Node.Type == Value {
+			for _, connection := range node.Connections[i].Node.Connections {
+				if connection.Node.Type == Object {
+					commonFeatures[connection.Node]++
+				}
 			}
 		}
 	}
+	for _, object := range asonn.graph().nodes(Object) {
+		outCorrelations[length-commonFeatures[object]-1]++
+	}
 	return outCorrelations, nil
 }
 
@@ -590,6 +717,11 @@ func (asonn Asonn) expandCombination(node *Node) error {
 						weedlessExtensionNotDone = true
 					}
 				}
+				if category := possibleExpansions[i].Category; category != nil && isPureCategory(category, getClassOfObject(node)) {
+					possibleExpansions[i].Range.Value = append(possibleExpansions[i].Range.Value.([]interface{}), category.Value)
+					addConnection(possibleExpansions[i].Range, category, 1)
+					weedlessExtensionNotDone = true
+				}
 			}
 			if weedlessExtensionNotDone {
 				asonn.addRepresentedObjects(node)
@@ -619,6 +751,13 @@ func (asonn Asonn) expandCombination(node *Node) error {
 					rangeToExpand = possibleExpansions[i].Range
 				}
 			}
+			if possibleExpansions[i].Category != nil {
+				coeff := asonn.calculateCategoryCoefficient(possibleExpansions[i].Category, possibleExpansions[i].Range)
+				if maxCoeff < coeff {
+					nodeToAdd = possibleExpansions[i].Category
+					rangeToExpand = possibleExpansions[i].Range
+				}
+			}
 		}
 		if nodeToAdd != nil && rangeToExpand != nil {
 			if shouldContinue = asonn.expandWith(nodeToAdd, rangeToExpand, node); shouldContinue {
@@ -628,7 +767,9 @@ func (asonn Asonn) expandCombination(node *Node) error {
 	}
 	for i := range node.Connections {
 		if node.Connections[i].Node.Type == Range {
-			reduceRange(node.Connections[i].Node)
+			if err := reduceRange(node.Connections[i].Node); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -639,16 +780,23 @@ func (asonn Asonn) getPossibleExpansions(node *Node) ([]Expansions, error) {
 	for i := range node.Connections {
 		if node.Connections[i].Node.Type == Range {
 			expansions := Expansions{Range: node.Connections[i].Node, Smaller: nil, Bigger: nil}
-			for j := range node.Connections[i].Node.Connections {
-				valRange, ok := node.Connections[i].Node.Value.([]interface{})
-				if !ok {
-					return nil, errors.New("Range doesn't store []interface{}")
+			valRange, ok := node.Connections[i].Node.Value.([]interface{})
+			if !ok {
+				return nil, errors.New("Range doesn't store []interface{}")
+			}
+			if len(valRange) > 0 {
+				if _, categorical := valRange[0].(string); categorical {
+					expansions.Category = asonn.graph().nextCategory(node.Connections[i].Node, node)
+					expansionOptions = append(expansionOptions, expansions)
+					continue
 				}
+			}
+			minVal, maxVal, err := minMax(valRange)
+			if err != nil {
+				return nil, err
+			}
+			for j := range node.Connections[i].Node.Connections {
 				if node.Connections[i].Node.Connections[j].Node.Type == Value {
-					minVal, maxVal, err := minMax(valRange)
-					if err != nil {
-						return nil, err
-					}
 					if node.Connections[i].Node.Connections[j].Node.Value == minVal {
 						nextSmaller, reachedMin, err := getNextSmaller(node.Connections[i].Node.Connections[j].Node)
 						if err != nil {
@@ -717,15 +865,15 @@ func (asonn Asonn) calculateMinusCoefficient(node *Node) (float64, error) {
 	if node.Type != Range {
 		return 0.0, errors.New("Not a range node")
 	}
+	graph := asonn.graph()
+	rangeMin, _, _ := minMax(node.Value.([]interface{}))
+	rangeMinFloat, _ := convertToFloat64(rangeMin)
 	var smallerValues []*Node
 	for i := range node.Connections {
 		if node.Connections[i].Node.Type == Feature {
-			for j := range node.Connections[i].Node.Connections {
-				nodeVal, _ := convertToFloat64(node.Connections[i].Node.Connections[j].Node.Value)
-				rangeMin, _, _ := minMax(node.Value.([]interface{}))
-				rangeMinFloat, _ := convertToFloat64(rangeMin)
-				if node.Connections[i].Node.Connections[j].Node.Type == Value && nodeVal < rangeMinFloat {
-					smallerValues = append(smallerValues, node.Connections[i].Node.Connections[j].Node)
+			for _, valueNode := range graph.featureValues[node.Connections[i].Node] {
+				if graph.valueFloats[valueNode] < rangeMinFloat {
+					smallerValues = append(smallerValues, valueNode)
 				}
 			}
 		}
@@ -741,15 +889,15 @@ func (asonn Asonn) calculatePlusCoefficient(node *Node) (float64, error) {
 	if node.Type != Range {
 		return 0.0, errors.New("Not a range node")
 	}
+	graph := asonn.graph()
+	_, rangeMax, _ := minMax(node.Value.([]interface{}))
+	rangeMaxFloat, _ := convertToFloat64(rangeMax)
 	var biggerValues []*Node
 	for i := range node.Connections {
 		if node.Connections[i].Node.Type == Feature {
-			for j := range node.Connections[i].Node.Connections {
-				nodeVal, _ := convertToFloat64(node.Connections[i].Node.Connections[j].Node.Value)
-				_, rangeMax, _ := minMax(node.Value.([]interface{}))
-				rangeMaxFloat, _ := convertToFloat64(rangeMax)
-				if node.Connections[i].Node.Connections[j].Node.Type == Value && nodeVal > rangeMaxFloat {
-					biggerValues = append(biggerValues, node.Connections[i].Node.Connections[j].Node)
+			for _, valueNode := range graph.featureValues[node.Connections[i].Node] {
+				if graph.valueFloats[valueNode] > rangeMaxFloat {
+					biggerValues = append(biggerValues, valueNode)
 				}
 			}
 		}
@@ -783,12 +931,9 @@ func (asonn Asonn) expandWith(node *Node, rangeNode *Node, combinationNode *Node
 			for k := range combinationNode.Connections {
 				if combinationNode.Connections[k].Node.Type == Range {
 					if rangeFeature, _ := getFeatureType(combinationNode.Connections[k].Node); rangeFeature == feature {
-						rangeMin, rangeMax, _ := minMax(combinationNode.Connections[k].Node.Value.([]interface{}))
-						rangeMinFloat, _ := convertToFloat64(rangeMin)
-						rangeMaxFloat, _ := convertToFloat64(rangeMax)
-						if val, _ := convertToFloat64(objectWeeds[i].Connections[j].Node.Value); val <= rangeMaxFloat && val >= rangeMinFloat {
+						if rangeHolds(combinationNode.Connections[k].Node.Value.([]interface{}), objectWeeds[i].Connections[j].Node.Value) {
 							counter += 1
-							if counter == asonn.getFeaturesNumber()-1 {
+							if counter == asonn.getFeaturesNumber()-float64(asonn.config().WeedTolerance) {
 								canAdd = false
 							}
 						}
@@ -807,12 +952,16 @@ func (asonn Asonn) expandWith(node *Node, rangeNode *Node, combinationNode *Node
 
 func (asonn *Asonn) removeValueAndObjectNodes() {
 	var filtered []*Node
+	asonn.samples = nil
 	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type != Value && asonn.Nodes[i].Type != Object {
+		if asonn.Nodes[i].Type != Value && asonn.Nodes[i].Type != Object || isTargetValue(asonn.Nodes[i]) {
 			filtered = append(filtered, asonn.Nodes[i])
+		} else {
+			asonn.samples = append(asonn.samples, asonn.Nodes[i])
 		}
 	}
 	asonn.Nodes = filtered
+	asonn.Reindex()
 }
 
 func (asonn Asonn) updateRangeToCombinationConnectionWeights() {
@@ -847,42 +996,31 @@ func (asonn Asonn) calculate_7_18(node *Node, rangeNode *Node) float64 {
 	nodeValue, _ := convertToFloat64(node.Value)
 	rangeMin, _, _ := minMax(rangeNode.Value.([]interface{}))
 	minVal, _ := convertToFloat64(rangeMin)
-	featureRange, _ := getFeatureRange(rangeNode)
-	return math.Pow(1-(nodeValue-minVal)/featureRange, 2)
+	featureRange, _ := asonn.getFeatureRange(rangeNode)
+	return math.Pow(1-(nodeValue-minVal)/featureRange, asonn.config().DistanceExponent)
 }
 
 func (asonn Asonn) calculate_7_19(node *Node, rangeNode *Node) float64 {
 	nodeValue, _ := convertToFloat64(node.Value)
 	_, rangeMax, _ := minMax(rangeNode.Value.([]interface{}))
 	maxVal, _ := convertToFloat64(rangeMax)
-	featureRange, _ := getFeatureRange(rangeNode)
-	return math.Pow(1-(maxVal-nodeValue)/featureRange, 2)
+	featureRange, _ := asonn.getFeatureRange(rangeNode)
+	return math.Pow(1-(maxVal-nodeValue)/featureRange, asonn.config().DistanceExponent)
 }
 
-func getFeatureRange(node *Node) (float64, error) {
+func (asonn Asonn) getFeatureRange(node *Node) (float64, error) {
 	if node.Type != Value && node.Type != Range {
 		return 0, errors.New("Not a value or range node")
 	}
-	for i := range node.Connections {
-		if node.Connections[i].Node.Type == Feature {
-			minVal, _ := convertToFloat64(node.Connections[i].Node.Connections[0].Node.Value)
-			maxVal, _ := convertToFloat64(node.Connections[i].Node.Connections[0].Node.Value)
-			for j := range node.Connections[i].Node.Connections {
-				newVal, _ := convertToFloat64(node.Connections[i].Node.Connections[j].Node.Value)
-				if newVal < minVal {
-					minVal = newVal
-				} else if newVal > maxVal {
-					maxVal = newVal
-				}
-			}
-			return maxVal - minVal, nil
-		}
+	feature, err := getFeatureConnection(node)
+	if err != nil {
+		return 0, errors.New("Range not found")
 	}
-	return 0, errors.New("Range not found")
+	return asonn.graph().featureSpans[feature], nil
 }
 
 func (asonn Asonn) calculate_7_20(node *Node) float64 {
-	return math.Pow(1/(1+asonn.countCombinationConnections(node)), 2)
+	return math.Pow(1/(1+asonn.countCombinationConnections(node)), asonn.config().CombinationExponent)
 }
 
 func (asonn Asonn) calculate_7_21(node *Node, rangeNode *Node) float64 {
@@ -913,26 +1051,13 @@ func (asonn Asonn) calculate7_36(node *Node) float64 {
 }
 
 func (asonn Asonn) calculate7_37(node *Node) float64 {
-	outSNCount := 0
-	class := getClassOfObject(node)
-	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type == Object {
-			if getClassOfObject(asonn.Nodes[i]) != class {
-				outSNCount += 1
-			}
-		}
-	}
+	graph := asonn.graph()
+	outSNCount := len(graph.nodes(Object)) - graph.classObjects[getClassOfObject(node)]
 	return float64(outSNCount) * math.Pow(asonn.getFeaturesNumber(), 2)
 }
 
 func (asonn Asonn) getFeaturesNumber() float64 {
-	counter := 0.0
-	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type == Feature {
-			counter += 1
-		}
-	}
-	return counter
+	return asonn.graph().featuresNumber()
 }
 
 func (asonn Asonn) countCombinationConnections(node *Node) float64 {
@@ -949,12 +1074,12 @@ func (asonn Asonn) calculate_7_25(node *Node, rangeNode *Node) float64 {
 	counter := 0.0
 	// node should be Object type
 	// rangeNode should be Range type
+	minVal, maxVal, _ := minMax(rangeNode.Value.([]interface{}))
+	minValFloat, _ := convertToFloat64(minVal)
+	maxValFloat, _ := convertToFloat64(maxVal)
 	for i := range node.Connections {
 		if node.Connections[i].Node.Type == Value {
 			val, _ := convertToFloat64(node.Connections[i].Node.Value)
-			minVal, maxVal, _ := minMax(rangeNode.Value.([]interface{}))
-			minValFloat, _ := convertToFloat64(minVal)
-			maxValFloat, _ := convertToFloat64(maxVal)
 			if val <= maxValFloat && val >= minValFloat {
 				counter += 1
 			}
@@ -983,26 +1108,6 @@ func max(values []float64) float64 {
 	return maxValue
 }
 
-func (asonn Asonn) getFirstNotRepresentedObjectIndex() int {
-	for i := range asonn.Nodes {
-		if asonn.Nodes[i].Type == Object {
-			for j := range asonn.Nodes[i].Connections {
-				if asonn.Nodes[i].Connections[j].Node.Type == Combination {
-					break
-				}
-			}
-			return i
-		}
-	}
-	return -1
-}
-
-func (asonn Asonn) resetActivations() {
-	for i := range asonn.Nodes {
-		asonn.Nodes[i].Activation = 0
-	}
-}
-
 func countObjectConnections(node *Node) int {
 	counter := 0
 	for i := range node.Connections {
@@ -1018,7 +1123,7 @@ func countObjectConnectionsFromClass(node *Node, class string) int {
 	for i := range node.Connections {
 		if node.Connections[i].Node.Type == Object {
 			for j := range node.Connections[i].Node.Connections {
-				if node.Connections[i].Node.Connections[j].Node.Type == Class && node.Connections[i].Node.Connections[j].Node.Value == class {
+				if node.Connections[i].Node.Connections[j].Node.Type == Class && classLabel(node.Connections[i].Node.Connections[j].Node) == class {
 					counter += 1
 				}
 			}
@@ -1030,12 +1135,24 @@ func countObjectConnectionsFromClass(node *Node, class string) int {
 func getClassOfObject(node *Node) string {
 	for i := range node.Connections {
 		if node.Connections[i].Node.Type == Class {
-			return node.Connections[i].Node.Value.(string)
+			return classLabel(node.Connections[i].Node)
 		}
 	}
 	return ""
 }
 
+// classLabel returns the label of a Class node, formatted when it isn't a
+// string.
+func classLabel(classNode *Node) string {
+	switch label := classNode.Value.(type) {
+	case string:
+		return label
+	case [2]interface{}:
+		return fmt.Sprintf("[%v, %v]", label[0], label[1])
+	}
+	return fmt.Sprint(classNode.Value)
+}
+
 func getBiggerCorrelation(currentMax []int, pretender []int) ([]int, bool) {
 	for i := range currentMax {
 		if pretender[i] > currentMax[i] {
@@ -1045,13 +1162,19 @@ func getBiggerCorrelation(currentMax []int, pretender []int) ([]int, bool) {
 	return currentMax, false
 }
 
-func reduceRange(node *Node) {
+func reduceRange(node *Node) error {
 	if node.Type == Range {
+		if categories, ok := newCategories(node.Value.([]interface{})); ok {
+			node.Value = categories
+			return nil
+		}
 		min, max, err := minMax(node.Value.([]interface{}))
-		if err == nil {
-			node.Value = [2]interface{}{min, max}
+		if err != nil {
+			return err
 		}
+		node.Value = [2]interface{}{min, max}
 	}
+	return nil
 }
 
 func minMax(slice []interface{}) (interface{}, interface{}, error) {
@@ -1059,7 +1182,7 @@ func minMax(slice []interface{}) (interface{}, interface{}, error) {
 		return nil, nil, errors.New("Empty slice")
 	}
 	if !isNumeric(slice[0]) {
-		return nil, nil, errors.New("Not a numeric type")
+		return nil, nil, fmt.Errorf("%w: %v", ErrNonNumericRange, slice[0])
 	}
 	min, minErr := convertToFloat64(slice[0])
 	max, maxErr := convertToFloat64(slice[0])
@@ -1067,10 +1190,14 @@ func minMax(slice []interface{}) (interface{}, interface{}, error) {
 		return nil, nil, errors.New("Conversion unsuccessful")
 	}
 	for i := range slice {
-		if val, _ := convertToFloat64(slice[i]); val < min {
+		val, err := convertToFloat64(slice[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrNonNumericRange, slice[i])
+		}
+		if val < min {
 			min = val
 		}
-		if val, _ := convertToFloat64(slice[i]); val > max {
+		if val > max {
 			max = val
 		}
 	}
@@ -1147,7 +1274,8 @@ type Node struct {
 	Value       interface{}
 	Connections ConnectionSlice
 	Type        string
-	Activation  float64
+	// Activation is not written by inference, which keeps activations per call.
+	Activation float64
 }
 
 const (
@@ -1157,6 +1285,7 @@ const (
 	Class       = "Class"
 	Range       = "Range"
 	Combination = "Combination"
+	Target      = "Target"
 )
 
 func NewNode(value interface{}, nodeType string) Node {
@@ -1173,11 +1302,11 @@ func (node Node) countValueConnections() int {
 	return counter
 }
 
-func (node *Node) activate(value interface{}) *Node {
+func (node *Node) activate(value float64, membership MembershipFunc, scale float64, state activations) *Node {
 	if node.Type == Range {
-		node.Activation = node.getActivation(value)
+		state[node] = node.activationAt(value, membership, scale)
 	}
-	if node.Activation != 0.0 {
+	if state[node] != 0.0 {
 		for i := range node.Connections {
 			if node.Connections[i].Node.Type == Combination {
 				return node.Connections[i].Node
@@ -1187,31 +1316,34 @@ func (node *Node) activate(value interface{}) *Node {
 	return nil
 }
 
-func (node *Node) activateCombination() {
+// activateCategory fully activates a categorical range holding the input.
+func (node *Node) activateCategory(state activations) *Node {
+	state[node] = 1.0
+	for i := range node.Connections {
+		if node.Connections[i].Node.Type == Combination {
+			return node.Connections[i].Node
+		}
+	}
+	return nil
+}
+
+func (node *Node) activateCombination(state activations) {
 	if node.Type == Combination {
 		for i := range node.Connections {
 			if node.Connections[i].Node.Type == Range {
-				node.Activation += node.Connections[i].Node.Activation * node.Connections[i].Weight
+				state[node] += state[node.Connections[i].Node] * node.Connections[i].Weight
 			}
 		}
 	}
 }
 
-func (node Node) getActivation(value interface{}) float64 {
+// activationAt applies membership to the range, falling off over scale times
+// its width outside of it.
+func (node Node) activationAt(val float64, membership MembershipFunc, scale float64) float64 {
 	activation := 0.0
-	val, err := strconv.ParseFloat(value.(string), 64)
-	if err != nil {
-		valInt, _ := strconv.Atoi(value.(string))
-		val = float64(valInt)
-	}
 	if node.Type == Range {
-		min, _ := node.Value.([2]interface{})[0].(float64)
-		max, _ := node.Value.([2]interface{})[1].(float64)
-		if val >= min && val <= max {
-			activation = 1.0
-		} else {
-			activation = math.Pow(math.E, (1-math.Pow((2*val-max-min)/(max-min), 2))/2)
-		}
+		min, max, _ := rangeBounds(&node)
+		activation = membership.Activation(val, min, max, scale*(max-min))
 	}
 	return activation
 }
@@ -1311,6 +1443,8 @@ type Expansions struct {
 	Range   *Node
 	Smaller *Node
 	Bigger  *Node
+	// Category is set instead of Smaller and Bigger for categorical ranges.
+	Category *Node
 }
 
 func tryToReuseNode(value interface{}, nodes []*Node, i int) (*Node, bool) {
@@ -1343,28 +1477,6 @@ func convertToCorrectType(value string) interface{} {
 	return value
 }
 
-func countCommonFeatures(first *Node, second *Node) (int, error) {
-	if first.Type != Object || second.Type != Object {
-		return 0, errors.New("Not an object node")
-	}
-	counter := 0
-	for i := range first.Connections {
-		if first.Connections[i].Node.Type == Value {
-			firstFeature, firstErr := getFeatureType(first.Connections[i].Node)
-			for j := range second.Connections {
-				secondFeature, secondErr := getFeatureType(second.Connections[j].Node)
-				if !(firstErr == nil) || !(secondErr == nil) {
-					break
-				}
-				if second.Connections[j].Node.Type == Value && first.Connections[i].Node.Value == second.Connections[j].Node.Value && firstFeature == secondFeature {
-					counter++
-				}
-			}
-		}
-	}
-	return counter, nil
-}
-
 func getFeatureType(node *Node) (interface{}, error) {
 	if node.Type != Value && node.Type != Range {
 		return nil, errors.New("Not a value or range node")
@@ -1389,9 +1501,9 @@ func getFeatureConnection(node *Node) (*Node, error) {
 	return nil, errors.New("No connection to feature node")
 }
 
-func contains(nodes []*Node, combinationNode *Node) bool {
+func containsNode(nodes []*Node, combinationNode *Node) bool {
 	for _, node := range nodes {
-		if node.Value == combinationNode.Value {
+		if node == combinationNode {
 			return true
 		}
 	}
@@ -0,0 +1,99 @@
+package gasonn
+
+import (
+	"sort"
+	"strings"
+)
+
+// Categories is the value of a reduced Range node of a categorical feature,
+// the sorted categories the range holds.
+type Categories []string
+
+func (categories Categories) contains(category string) bool {
+	i := sort.SearchStrings(categories, category)
+	return i < len(categories) && categories[i] == category
+}
+
+// with returns a copy of categories that holds category too.
+func (categories Categories) with(category string) Categories {
+	widened := append(Categories(nil), categories...)
+	if !widened.contains(category) {
+		widened = append(widened, category)
+		sort.Strings(widened)
+	}
+	return widened
+}
+
+func (categories Categories) String() string {
+	return "{" + strings.Join(categories, ", ") + "}"
+}
+
+// newCategories reduces the values of a range of a categorical feature.
+func newCategories(values []interface{}) (Categories, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+	if _, ok := values[0].(string); !ok {
+		return nil, false
+	}
+	var categories Categories
+	for _, value := range values {
+		category, ok := value.(string)
+		if !ok {
+			return nil, false
+		}
+		if !categories.contains(category) {
+			categories = append(categories, category)
+			sort.Strings(categories)
+		}
+	}
+	return categories, true
+}
+
+// rangeHolds reports whether value is within the values of an unreduced range.
+func rangeHolds(values []interface{}, value interface{}) bool {
+	if category, ok := value.(string); ok {
+		for _, other := range values {
+			if other == category {
+				return true
+			}
+		}
+		return false
+	}
+	rangeMin, rangeMax, _ := minMax(values)
+	rangeMinFloat, _ := convertToFloat64(rangeMin)
+	rangeMaxFloat, _ := convertToFloat64(rangeMax)
+	val, _ := convertToFloat64(value)
+	return val <= rangeMaxFloat && val >= rangeMinFloat
+}
+
+// nextCategory returns the category a categorical range of combination can
+// take in, preferring categories of objects of its class only and then those
+// with most objects of its class.
+func (graph *graphIndex) nextCategory(rangeNode *Node, combination *Node) *Node {
+	values, _ := rangeNode.Value.([]interface{})
+	class := getClassOfObject(combination)
+	var best *Node
+	bestPure, bestSeeds := false, -1
+	for _, valueNode := range graph.featureValues[graph.rangeFeatures[rangeNode]] {
+		if rangeHolds(values, valueNode.Value) {
+			continue
+		}
+		seeds := countObjectConnectionsFromClass(valueNode, class)
+		pure := seeds == countObjectConnections(valueNode)
+		if seeds > 0 && (pure && !bestPure || pure == bestPure && seeds > bestSeeds) {
+			best, bestPure, bestSeeds = valueNode, pure, seeds
+		}
+	}
+	return best
+}
+
+func isPureCategory(valueNode *Node, class string) bool {
+	return countObjectConnectionsFromClass(valueNode, class) == countObjectConnections(valueNode)
+}
+
+// calculateCategoryCoefficient is formula 7.16 for a category, whose distance
+// term 7.18 is 1 as categories are not ordered.
+func (asonn Asonn) calculateCategoryCoefficient(node *Node, rangeNode *Node) float64 {
+	return asonn.calculate_7_20(node) - asonn.calculate_7_21(node, rangeNode)
+}
@@ -0,0 +1,146 @@
+package gasonn
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var categoricalX = [][]string{
+	{"colour", "size", "weight"},
+	{"red", "small", "1.0"},
+	{"red", "large", "1.2"},
+	{"green", "small", "1.1"},
+	{"blue", "large", "3.0"},
+	{"blue", "small", "3.2"},
+	{"yellow", "large", "3.1"},
+	{"green", "large", "1.3"},
+	{"yellow", "small", "2.9"},
+}
+
+var categoricalY = []string{"target", "p", "p", "p", "n", "n", "n", "p", "n"}
+
+func classifyRow(tb testing.TB, asonn *Asonn, row []string) string {
+	tb.Helper()
+	predictions, err := asonn.Classify([][]string{categoricalX[0], row})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return predictions[0].Label
+}
+
+func TestCategorical(t *testing.T) {
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		asonn, err := Train(categoricalX, categoricalY, WithStrategy(strategy))
+		if err != nil {
+			t.Fatalf("Strategy %d: %v", strategy, err)
+		}
+		for _, node := range asonn.Nodes {
+			if node.Type != Range {
+				continue
+			}
+			feature, err := getFeatureConnection(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, isCategories := node.Value.(Categories)
+			if isCategories != (feature.Value != "weight") {
+				t.Errorf("Strategy %d: range %v of feature %v", strategy, node.Value, feature.Value)
+			}
+		}
+		if got := accuracy(t, asonn, categoricalX, categoricalY); got != 1 {
+			t.Errorf("Strategy %d: accuracy %f on the training data", strategy, got)
+		}
+	}
+}
+
+func TestCategoricalSaveAndRules(t *testing.T) {
+	asonn, err := Train(categoricalX, categoricalY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err := asonn.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := accuracy(t, loaded, categoricalX, categoricalY); got != 1 {
+		t.Errorf("Accuracy %f after loading", got)
+	}
+	rules, err := loaded.ExtractRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	categorical := 0
+	for _, rule := range rules {
+		for _, condition := range rule.Conditions {
+			if condition.Categories != nil {
+				categorical++
+				if !strings.Contains(rule.String(), condition.Feature+" in {") {
+					t.Errorf("Rule %q doesn't list categories of %s", rule, condition.Feature)
+				}
+			}
+		}
+	}
+	if categorical == 0 {
+		t.Errorf("No rule has a categorical condition")
+	}
+	buffer.Reset()
+	if err := WriteRules(&buffer, rules, RulesJSON); err != nil {
+		t.Fatal(err)
+	}
+	var document []map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range document {
+		for _, condition := range rule["conditions"].([]interface{}) {
+			condition := condition.(map[string]interface{})
+			_, hasCategories := condition["categories"]
+			_, hasMin := condition["min"]
+			if hasCategories == hasMin {
+				t.Errorf("Condition %v", condition)
+			}
+		}
+	}
+}
+
+func TestCategoricalUpdates(t *testing.T) {
+	asonn, err := Train(categoricalX, categoricalY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := asonn.Learn([]string{"purple", "small", "1.1"}, "p"); err != nil {
+		t.Fatal(err)
+	}
+	if prediction := classifyRow(t, asonn, []string{"purple", "small", "1.1"}); prediction != "p" {
+		t.Errorf("New category classified as %s after learning it", prediction)
+	}
+	if _, err := Merge(asonn, asonn); err != nil {
+		t.Fatal(err)
+	}
+	for _, objectID := range []string{"O3", "O7"} {
+		if err := asonn.Forget(objectID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, node := range asonn.Nodes {
+		if categories, ok := node.Value.(Categories); ok && categories.contains("green") {
+			t.Errorf("Range %v keeps the category of forgotten objects", categories)
+		}
+	}
+	multiLayer, err := Train(categoricalX, categoricalY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := multiLayer.Learn([]string{"purple", "small", "1.1"}, "p"); err != nil {
+		t.Fatal(err)
+	}
+	if prediction := classifyRow(t, multiLayer, []string{"purple", "large", "1.2"}); prediction != "p" {
+		t.Errorf("New category classified as %s after learning it", prediction)
+	}
+}
@@ -0,0 +1,159 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+var ErrEmptyModel = errors.New("Model has no combinations")
+
+// Prediction is the classification of a single row.
+type Prediction struct {
+	Label string
+	// Scores holds the maximum combination activation of every class.
+	Scores map[string]float64
+	// Combination is the winning Combination node.
+	Combination *Node
+	// Ranking lists all classes ordered by descending score.
+	Ranking []ClassScore
+}
+
+type ClassScore struct {
+	Class string
+	Score float64
+}
+
+// TopK returns the k best scoring classes, none for k below 1.
+func (prediction Prediction) TopK(k int) []ClassScore {
+	if k > len(prediction.Ranking) {
+		k = len(prediction.Ranking)
+	}
+	if k < 0 {
+		k = 0
+	}
+	return prediction.Ranking[:k]
+}
+
+// Classify predicts the class of every row of test, whose first row holds
+// feature names. It works with both single and multi-layer models and may be
+// called from several goroutines at once. Columns are matched to features by
+// name, so their order doesn't matter. Features without a column are reported
+// with a MissingColumnError and cells the schema of the model can't read with
+// ErrInvalidValue.
+func (asonn *Asonn) Classify(test [][]string) ([]Prediction, error) {
+	classes, err := asonn.checkRows(test)
+	if err != nil {
+		return nil, err
+	}
+	test = asonn.fillMissing(test)
+	predictions := make([]Prediction, 0, len(test)-1)
+	for _, row := range test[1:] {
+		predictions = append(predictions, asonn.classifyRow(row, test[0], classes))
+	}
+	return predictions, nil
+}
+
+// PredictBatch classifies rows of test like Classify, spreading them over
+// workers goroutines, or GOMAXPROCS when workers is not positive. Predictions
+// are returned in input order.
+func (asonn *Asonn) PredictBatch(test [][]string, workers int) ([]Prediction, error) {
+	classes, err := asonn.checkRows(test)
+	if err != nil {
+		return nil, err
+	}
+	test = asonn.fillMissing(test)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	predictions := make([]Prediction, len(test)-1)
+	rows := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range rows {
+				predictions[row] = asonn.classifyRow(test[row+1], test[0], classes)
+			}
+		}()
+	}
+	for row := range predictions {
+		rows <- row
+	}
+	close(rows)
+	wg.Wait()
+	return predictions, nil
+}
+
+func (asonn *Asonn) checkRows(test [][]string) ([]string, error) {
+	if len(test) == 0 {
+		return nil, ErrEmptyData
+	}
+	classes, err := asonn.classes()
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range test[1:] {
+		if len(row) != len(test[0]) {
+			return nil, fmt.Errorf("%w: row %d has %d values, header has %d", ErrRaggedRow, i+1, len(row), len(test[0]))
+		}
+	}
+	if err := asonn.matchColumns(test[0]); err != nil {
+		return nil, err
+	}
+	if err := asonn.Schema.checkCells(test); err != nil {
+		return nil, err
+	}
+	return classes, nil
+}
+
+// classes returns the classes of the combinations of the model.
+func (asonn *Asonn) classes() ([]string, error) {
+	var classes []string
+	for _, node := range asonn.graph().nodes(Combination) {
+		if class := getClassOfObject(node); !containsString(classes, class) {
+			classes = append(classes, class)
+		}
+	}
+	if len(classes) == 0 {
+		return nil, ErrEmptyModel
+	}
+	return classes, nil
+}
+
+func (asonn *Asonn) classifyRow(row []string, features []string, classes []string) Prediction {
+	state, winner := asonn.activateCombinations(row, features)
+	return asonn.prediction(state, winner, classes)
+}
+
+func (asonn *Asonn) prediction(state activations, winner *Node, classes []string) Prediction {
+	prediction := Prediction{Combination: winner, Scores: make(map[string]float64)}
+	if winner != nil {
+		prediction.Label = getClassOfObject(winner)
+	}
+	for _, node := range asonn.graph().nodes(Combination) {
+		class := getClassOfObject(node)
+		if score, ok := prediction.Scores[class]; !ok || state[node] > score {
+			prediction.Scores[class] = state[node]
+		}
+	}
+	for _, class := range classes {
+		prediction.Ranking = append(prediction.Ranking, ClassScore{Class: class, Score: prediction.Scores[class]})
+	}
+	sort.SliceStable(prediction.Ranking, func(i, j int) bool {
+		return prediction.Ranking[i].Score > prediction.Ranking[j].Score
+	})
+	return prediction
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package gasonn
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		asonn, err := Train(trainX, trainY, WithStrategy(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		predictions, err := asonn.Classify(trainX)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(predictions) != len(trainX)-1 {
+			t.Fatalf("Got %d predictions instead of %d", len(predictions), len(trainX)-1)
+		}
+		for i, prediction := range predictions {
+			if prediction.Label != trainY[i+1] {
+				t.Errorf("Strategy %d: row %d classified as %s instead of %s", strategy, i+1, prediction.Label, trainY[i+1])
+			}
+			if prediction.Combination == nil || getClassOfObject(prediction.Combination) != prediction.Label {
+				t.Errorf("Winning combination doesn't match label")
+			}
+			if len(prediction.Ranking) != 2 || prediction.TopK(1)[0].Class != prediction.Label {
+				t.Errorf("Invalid ranking %v", prediction.Ranking)
+			}
+			if prediction.Scores[prediction.Label] != prediction.Ranking[0].Score {
+				t.Errorf("Score doesn't match ranking")
+			}
+		}
+	}
+}
+
+func TestPredict(t *testing.T) {
+	asonn, err := Train(trainX, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range asonn.Predict(trainX) {
+		if result < 0.99999 || result > 1.00001 {
+			t.Errorf("Invalid activation %f for training example", result)
+		}
+	}
+}
+
+func TestPredictSharedCombinationNames(t *testing.T) {
+	asonn, err := Train(overlappingX, overlappingY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range asonn.Nodes {
+		if node.Type == Combination {
+			node.Value = "C0"
+		}
+	}
+	// Only the first activated of the combinations sharing a name is summed,
+	// so rows of the class activated later no longer reach full activation.
+	partial := 0
+	for _, result := range asonn.Predict(overlappingX) {
+		if result < 0.99999 {
+			partial++
+		}
+	}
+	if partial == 0 {
+		t.Errorf("Combinations sharing a name were all summed")
+	}
+}
+
+func TestPredictBatch(t *testing.T) {
+	asonn, err := Train(overlappingX, overlappingY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := asonn.Classify(overlappingX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			predictions, err := asonn.PredictBatch(overlappingX, 3)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for j := range expected {
+				if predictions[j].Label != expected[j].Label || predictions[j].Scores[predictions[j].Label] != expected[j].Scores[expected[j].Label] {
+					t.Errorf("Row %d predicted as %v instead of %v", j+1, predictions[j], expected[j])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+type topKTestData struct {
+	k       int
+	classes []string
+}
+
+var topKTests = []topKTestData{
+	{-1, nil},
+	{0, nil},
+	{2, []string{"p", "n"}},
+	{5, []string{"p", "n", "x"}},
+}
+
+func TestTopK(t *testing.T) {
+	prediction := Prediction{Ranking: []ClassScore{{"p", 0.9}, {"n", 0.5}, {"x", 0.1}}}
+	for _, testData := range topKTests {
+		top := prediction.TopK(testData.k)
+		if len(top) != len(testData.classes) {
+			t.Errorf("TopK(%d) returned %v", testData.k, top)
+			continue
+		}
+		for i := range top {
+			if top[i].Class != testData.classes[i] {
+				t.Errorf("TopK(%d) returned %v", testData.k, top)
+			}
+		}
+	}
+}
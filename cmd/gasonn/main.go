@@ -0,0 +1,425 @@
+// Command gasonn trains, applies, evaluates and inspects ASONN classifiers
+// and regression models stored as model files.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jakubkosno/gasonn"
+	"github.com/jakubkosno/gasonn/dataset"
+	"github.com/jakubkosno/gasonn/eval"
+)
+
+const usage = `Usage: gasonn <command> [flags]
+
+Commands:
+  train    train a model from a CSV/TSV file
+  predict  predict labels and class probabilities, or estimates of regression models
+  eval     evaluate a model on labelled data
+  inspect  show node counts and rules of a model
+  merge    merge models trained on separate data
+  fetch    download PMLB datasets into a local cache directory
+
+Run gasonn <command> -h for command flags.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "gasonn:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return flag.ErrHelp
+	}
+	switch args[0] {
+	case "train":
+		return train(args[1:], stdout)
+	case "predict":
+		return predict(args[1:], stdout)
+	case "eval":
+		return evaluate(args[1:], stdout)
+	case "inspect":
+		return inspect(args[1:], stdout)
+	case "merge":
+		return merge(args[1:], stdout)
+	case "fetch":
+		return fetch(args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func train(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("train", flag.ContinueOnError)
+	data := flags.String("data", "", "training CSV or TSV file with a header row")
+	target := flags.String("target", "", "name of the label column (default last column)")
+	layers := flags.String("layers", "single", "combination layers: single or multi")
+	missing := flags.String("missing", "skip", "missing value policy: skip, mean, median, mode, associative or category")
+	regression := flags.Bool("regression", false, "the target is continuous and the model estimates it")
+	out := flags.String("out", "model.gasonn", "model file to write")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *data == "" {
+		return errors.New("train: -data is required")
+	}
+	var strategy gasonn.Strategy
+	switch *layers {
+	case "single":
+		strategy = gasonn.SingleLayer
+	case "multi":
+		strategy = gasonn.MultiLayer
+	default:
+		return fmt.Errorf("train: unknown -layers %q", *layers)
+	}
+	policies := map[string]gasonn.MissingPolicy{
+		"skip":        gasonn.SkipMissing,
+		"mean":        gasonn.ImputeMean,
+		"median":      gasonn.ImputeMedian,
+		"mode":        gasonn.ImputeMode,
+		"associative": gasonn.ImputeAssociative,
+		"category":    gasonn.MissingAsCategory,
+	}
+	policy, ok := policies[*missing]
+	if !ok {
+		return fmt.Errorf("train: unknown -missing %q", *missing)
+	}
+	x, y, err := readLabelled(*data, *target)
+	if err != nil {
+		return err
+	}
+	trainModel := gasonn.Train
+	if *regression {
+		trainModel = gasonn.TrainRegression
+	}
+	asonn, err := trainModel(x, y, gasonn.WithStrategy(strategy), gasonn.WithMissing(policy))
+	if err != nil {
+		return err
+	}
+	if err := saveModel(asonn, *out); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "trained on %d rows, wrote %s\n", len(x)-1, *out)
+	return nil
+}
+
+func predict(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("predict", flag.ContinueOnError)
+	modelFile := flags.String("model", "model.gasonn", "model file")
+	data := flags.String("data", "", "CSV or TSV file with a header row, unless -no-header is set")
+	target := flags.String("target", "", "label column to drop from the input, if present")
+	scores := flags.Bool("scores", false, "also write class probabilities")
+	noHeader := flags.Bool("no-header", false, "the data has no header row and follows the training column order")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *data == "" {
+		return errors.New("predict: -data is required")
+	}
+	if *noHeader && *target != "" {
+		return errors.New("predict: -target needs a header row")
+	}
+	asonn, err := loadModel(*modelFile)
+	if err != nil {
+		return err
+	}
+	x, err := dataset.ReadTable(*data)
+	if err != nil {
+		return err
+	}
+	if *noHeader {
+		x = append([][]string{asonn.Header()}, x...)
+	}
+	if *target != "" {
+		x, _, err = dataset.SplitTarget(x, *target)
+		if err != nil {
+			return err
+		}
+	}
+	if asonn.IsRegression() {
+		if *scores {
+			return errors.New("predict: -scores needs a classification model")
+		}
+		return writeEstimates(stdout, asonn, x)
+	}
+	predictions, err := asonn.Classify(x)
+	if err != nil {
+		return err
+	}
+	var probabilities []map[string]float64
+	var classes []string
+	if *scores {
+		if probabilities, err = asonn.PredictProba(x); err != nil {
+			return err
+		}
+		if len(predictions) > 0 {
+			for class := range predictions[0].Scores {
+				classes = append(classes, class)
+			}
+			sort.Strings(classes)
+		}
+	}
+	records := [][]string{append([]string{"label"}, classes...)}
+	for i, prediction := range predictions {
+		record := []string{prediction.Label}
+		for _, class := range classes {
+			record = append(record, fmt.Sprint(probabilities[i][class]))
+		}
+		records = append(records, record)
+	}
+	return writeCSV(stdout, records)
+}
+
+func writeEstimates(stdout io.Writer, asonn *gasonn.Asonn, x [][]string) error {
+	estimates, err := asonn.Regress(x)
+	if err != nil {
+		return err
+	}
+	records := [][]string{{"estimate", "low", "high"}}
+	for _, estimate := range estimates {
+		records = append(records, []string{fmt.Sprint(estimate.Value), fmt.Sprint(estimate.Low), fmt.Sprint(estimate.High)})
+	}
+	return writeCSV(stdout, records)
+}
+
+type reportWriter interface {
+	WriteText(w io.Writer) error
+	WriteCSV(w io.Writer) error
+	WriteJSON(w io.Writer) error
+}
+
+func evaluate(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	modelFile := flags.String("model", "model.gasonn", "model file")
+	data := flags.String("data", "", "labelled CSV or TSV file with a header row")
+	target := flags.String("target", "", "name of the label column (default last column)")
+	format := flags.String("format", "text", "report format: text, csv or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *data == "" {
+		return errors.New("eval: -data is required")
+	}
+	asonn, err := loadModel(*modelFile)
+	if err != nil {
+		return err
+	}
+	x, y, err := readLabelled(*data, *target)
+	if err != nil {
+		return err
+	}
+	var report reportWriter
+	if asonn.IsRegression() {
+		report, err = evaluateRegression(asonn, x, y)
+	} else {
+		report, err = evaluateClassification(asonn, x, y)
+	}
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "text":
+		return report.WriteText(stdout)
+	case "csv":
+		return report.WriteCSV(stdout)
+	case "json":
+		return report.WriteJSON(stdout)
+	default:
+		return fmt.Errorf("eval: unknown -format %q", *format)
+	}
+}
+
+func evaluateClassification(asonn *gasonn.Asonn, x [][]string, y []string) (*eval.Report, error) {
+	predictions, err := asonn.Classify(x)
+	if err != nil {
+		return nil, err
+	}
+	probabilities, err := asonn.PredictProba(x)
+	if err != nil {
+		return nil, err
+	}
+	var yTrue, yPred []string
+	var scores []map[string]float64
+	for i, prediction := range predictions {
+		if y[i+1] == "" {
+			continue
+		}
+		yTrue = append(yTrue, y[i+1])
+		yPred = append(yPred, prediction.Label)
+		scores = append(scores, probabilities[i])
+	}
+	return eval.EvaluateScores(yTrue, yPred, scores)
+}
+
+func evaluateRegression(asonn *gasonn.Asonn, x [][]string, y []string) (*eval.RegressionReport, error) {
+	estimates, err := asonn.Regress(x)
+	if err != nil {
+		return nil, err
+	}
+	var yTrue, yPred, low, high []float64
+	for i, estimate := range estimates {
+		if y[i+1] == "" {
+			continue
+		}
+		target, err := strconv.ParseFloat(y[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("eval: target %q in row %d is not a number", y[i+1], i+1)
+		}
+		yTrue = append(yTrue, target)
+		yPred = append(yPred, estimate.Value)
+		low = append(low, estimate.Low)
+		high = append(high, estimate.High)
+	}
+	return eval.EvaluateIntervals(yTrue, yPred, low, high)
+}
+
+func inspect(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	modelFile := flags.String("model", "model.gasonn", "model file")
+	rules := flags.String("rules", "", "also list rules: text, markdown or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	asonn, err := loadModel(*modelFile)
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int)
+	seen := make(map[*gasonn.Node]bool)
+	for _, node := range asonn.Nodes {
+		if !seen[node] {
+			seen[node] = true
+			counts[node.Type]++
+		}
+	}
+	for _, nodeType := range []string{gasonn.Feature, gasonn.Class, gasonn.Range, gasonn.Combination, gasonn.Value, gasonn.Object} {
+		if counts[nodeType] > 0 {
+			fmt.Fprintf(stdout, "%-12s %d\n", nodeType, counts[nodeType])
+		}
+	}
+	if *rules == "" {
+		return nil
+	}
+	var format gasonn.RuleFormat
+	switch strings.ToLower(*rules) {
+	case "text":
+		format = gasonn.RulesText
+	case "markdown":
+		format = gasonn.RulesMarkdown
+	case "json":
+		format = gasonn.RulesJSON
+	default:
+		return fmt.Errorf("inspect: unknown -rules %q", *rules)
+	}
+	extracted, err := asonn.ExtractRules()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout)
+	return gasonn.WriteRules(stdout, extracted, format)
+}
+
+func merge(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	out := flags.String("out", "model.gasonn", "model file to write")
+	data := flags.String("data", "", "comma-separated labelled CSV or TSV files the models were trained on, in model order, to report accuracy on")
+	target := flags.String("target", "", "name of the label column (default last column)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		return errors.New("merge: at least two model files are required")
+	}
+	var shards []string
+	if *data != "" {
+		shards = strings.Split(*data, ",")
+		if len(shards) != flags.NArg() {
+			return fmt.Errorf("merge: %d data files for %d models", len(shards), flags.NArg())
+		}
+	}
+	var merged *gasonn.Asonn
+	for _, path := range flags.Args() {
+		asonn, err := loadModel(path)
+		if err != nil {
+			return err
+		}
+		if merged == nil {
+			merged = asonn
+		} else if merged, err = gasonn.Merge(merged, asonn); err != nil {
+			return err
+		}
+	}
+	for i, path := range shards {
+		x, y, err := readLabelled(path, *target)
+		if err != nil {
+			return err
+		}
+		accuracy, err := merged.Accuracy(x, y)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s: accuracy %.4f on %d samples\n", flags.Arg(i), accuracy, len(x)-1)
+	}
+	return saveModel(merged, *out)
+}
+
+func fetch(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	dir := flags.String("dir", "testdata/pmlb", "PMLB cache directory")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("fetch: no dataset names given")
+	}
+	cache := dataset.PMLBCache{Dir: *dir}
+	for _, name := range flags.Args() {
+		x, y, err := dataset.Online{}.Load(name)
+		if err != nil {
+			return err
+		}
+		if err := cache.Store(name, x, y); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "stored %s in %s\n", name, *dir)
+	}
+	return nil
+}
+
+func saveModel(asonn *gasonn.Asonn, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := asonn.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func loadModel(path string) (*gasonn.Asonn, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return gasonn.Load(file)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const trainingData = `class,a,b
+p,1.0,2.5
+p,1.2,2.7
+n,3.1,0.5
+n,3.3,0.7
+p,1.1,2.6
+n,3.0,0.4
+`
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "train.csv")
+	model := filepath.Join(dir, "model.gasonn")
+	if err := os.WriteFile(data, []byte(trainingData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := run([]string{"train", "-data", data, "-target", "class", "-layers", "multi", "-missing", "mean", "-out", model}, &out); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := run([]string{"predict", "-model", model, "-data", data, "-target", "class"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if labels := strings.Fields(out.String()); strings.Join(labels, " ") != "label p p n n p n" {
+		t.Errorf("Unexpected predictions %v", labels)
+	}
+	rows := filepath.Join(dir, "rows.csv")
+	if err := os.WriteFile(rows, []byte("1.1,2.6\n3.0,0.4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := run([]string{"predict", "-model", model, "-data", rows, "-no-header"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if labels := strings.Fields(out.String()); strings.Join(labels, " ") != "label p n" {
+		t.Errorf("Unexpected predictions %v without a header", labels)
+	}
+	out.Reset()
+	if err := run([]string{"eval", "-model", model, "-data", data, "-target", "class", "-format", "csv"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "accuracy,1,") {
+		t.Errorf("Unexpected report %s", out.String())
+	}
+	out.Reset()
+	if err := run([]string{"inspect", "-model", model, "-rules", "text"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Combination") || !strings.Contains(out.String(), "THEN p") {
+		t.Errorf("Unexpected inspect output %s", out.String())
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	lines := strings.SplitAfter(trainingData, "\n")
+	shards := map[string]string{
+		"east": lines[0] + lines[1] + lines[3] + lines[5],
+		"west": lines[0] + lines[2] + lines[4] + lines[6],
+	}
+	var models, files []string
+	var out bytes.Buffer
+	for name, shard := range shards {
+		data := filepath.Join(dir, name+".csv")
+		model := filepath.Join(dir, name+".gasonn")
+		if err := os.WriteFile(data, []byte(shard), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := run([]string{"train", "-data", data, "-target", "class", "-out", model}, &out); err != nil {
+			t.Fatal(err)
+		}
+		models = append(models, model)
+		files = append(files, data)
+	}
+	out.Reset()
+	merged := filepath.Join(dir, "merged.gasonn")
+	if err := run(append([]string{"merge", "-out", merged, "-data", strings.Join(files, ","), "-target", "class"}, models...), &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), "accuracy 1.0000 on 3 samples") != 2 {
+		t.Errorf("Unexpected merge output %s", out.String())
+	}
+	if _, err := os.Stat(merged); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRegression(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "train.csv")
+	model := filepath.Join(dir, "model.gasonn")
+	regressionData := strings.NewReplacer("p,", "1.2,", "n,", "7.5,").Replace(trainingData)
+	if err := os.WriteFile(data, []byte(regressionData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := run([]string{"train", "-data", data, "-target", "class", "-layers", "multi", "-regression", "-out", model}, &out); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := run([]string{"predict", "-model", model, "-data", data, "-target", "class"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Fields(out.String()); len(lines) != 7 || lines[0] != "estimate,low,high" || lines[1] != "1.2,1.2,1.2" {
+		t.Errorf("Unexpected estimates %v", lines)
+	}
+	if err := run([]string{"predict", "-model", model, "-data", data, "-target", "class", "-scores"}, &out); err == nil {
+		t.Error("Class probabilities of a regression model")
+	}
+	out.Reset()
+	if err := run([]string{"eval", "-model", model, "-data", data, "-target", "class", "-format", "csv"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "r2,1\n") || !strings.Contains(out.String(), "coverage,1\n") {
+		t.Errorf("Unexpected report %s", out.String())
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/jakubkosno/gasonn/dataset"
+)
+
+func readLabelled(path string, target string) ([][]string, []string, error) {
+	table, err := dataset.ReadTable(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target == "" {
+		target = table[0][len(table[0])-1]
+	}
+	return dataset.SplitTarget(table, target)
+}
+
+func writeCSV(w io.Writer, records [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
@@ -0,0 +1,106 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var (
+	ErrMissingColumn = errors.New("Missing column")
+	ErrUnknownColumn = errors.New("Unknown column")
+)
+
+// MissingColumnError lists features of the model that prediction input has
+// no column for.
+type MissingColumnError struct {
+	Columns []string
+}
+
+func (err *MissingColumnError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrMissingColumn, strings.Join(err.Columns, ", "))
+}
+
+func (err *MissingColumnError) Unwrap() error {
+	return ErrMissingColumn
+}
+
+// UnknownColumnError lists columns of prediction input the model has no
+// feature for and ignores.
+type UnknownColumnError struct {
+	Columns []string
+}
+
+func (err *UnknownColumnError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrUnknownColumn, strings.Join(err.Columns, ", "))
+}
+
+func (err *UnknownColumnError) Unwrap() error {
+	return ErrUnknownColumn
+}
+
+// warn passes a problem with prediction input that doesn't stop prediction to
+// asonn.Warn, or logs it when Warn is nil.
+func (asonn *Asonn) warn(err error) {
+	if asonn.Warn != nil {
+		asonn.Warn(err)
+		return
+	}
+	log.Printf("gasonn: %v", err)
+}
+
+// Header returns the feature names in the order the model was trained with,
+// the order ClassifyRows reads cells in.
+func (asonn *Asonn) Header() []string {
+	if asonn.Schema != nil {
+		header := make([]string, len(asonn.Schema))
+		for j, column := range asonn.Schema {
+			header[j] = column.Name
+		}
+		return header
+	}
+	var header []string
+	for _, feature := range asonn.graph().nodes(Feature) {
+		header = append(header, fmt.Sprint(feature.Value))
+	}
+	return header
+}
+
+// ClassifyRows classifies rows without a header row, whose cells follow
+// Header.
+func (asonn *Asonn) ClassifyRows(rows [][]string) ([]Prediction, error) {
+	return asonn.Classify(append([][]string{asonn.Header()}, rows...))
+}
+
+// matchColumns matches the columns of header to the features of the model by
+// name. Columns of other names are passed to warn in an UnknownColumnError and
+// features without a column are returned in a MissingColumnError.
+func (asonn *Asonn) matchColumns(header []string) error {
+	expected := make(map[string]int)
+	for _, name := range asonn.Header() {
+		expected[name]++
+	}
+	var unknown []string
+	for _, name := range header {
+		if expected[name] == 0 {
+			unknown = append(unknown, name)
+			continue
+		}
+		expected[name]--
+	}
+	if len(unknown) > 0 {
+		asonn.warn(&UnknownColumnError{Columns: unknown})
+	}
+	var missing []string
+	for _, name := range asonn.Header() {
+		if expected[name] > 0 {
+			missing = append(missing, name)
+			expected[name]--
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingColumnError{Columns: missing}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func labels(predictions []Prediction) []string {
+	var labels []string
+	for _, prediction := range predictions {
+		labels = append(labels, prediction.Label)
+	}
+	return labels
+}
+
+func TestColumnMatching(t *testing.T) {
+	asonn, err := Train(trainX, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warnings []error
+	asonn.Warn = func(err error) {
+		warnings = append(warnings, err)
+	}
+	expected, err := asonn.Classify(trainX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reordered := [][]string{{"id", "b", "a"}}
+	for i, row := range trainX[1:] {
+		reordered = append(reordered, []string{fmt.Sprint(i), row[1], row[0]})
+	}
+	predictions, err := asonn.Classify(reordered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(labels(predictions), labels(expected)) {
+		t.Errorf("Reordered columns classified as %v instead of %v", labels(predictions), labels(expected))
+	}
+	var unknown *UnknownColumnError
+	if len(warnings) != 1 || !errors.As(warnings[0], &unknown) || !reflect.DeepEqual(unknown.Columns, []string{"id"}) {
+		t.Errorf("Got warnings %v instead of one about column id", warnings)
+	}
+	rows, err := asonn.ClassifyRows(trainX[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(labels(rows), labels(expected)) {
+		t.Errorf("Rows without a header classified as %v instead of %v", labels(rows), labels(expected))
+	}
+	_, err = asonn.Classify([][]string{{"a", "c"}, {"1.0", "2.0"}})
+	var missing *MissingColumnError
+	if !errors.As(err, &missing) || !errors.Is(err, ErrMissingColumn) {
+		t.Fatalf("Got error %v instead of %v", err, ErrMissingColumn)
+	}
+	if !reflect.DeepEqual(missing.Columns, []string{"b"}) {
+		t.Errorf("Missing columns %v instead of [b]", missing.Columns)
+	}
+	warnings = nil
+	asonn.Predict([][]string{{"a"}, {"1.0"}})
+	if len(warnings) != 1 || !errors.As(warnings[0], &missing) || !reflect.DeepEqual(missing.Columns, []string{"b"}) {
+		t.Errorf("Predict warned %v instead of a missing column b", warnings)
+	}
+}
@@ -0,0 +1,110 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidConfig = errors.New("Invalid configuration")
+
+// Config holds the hyperparameters of training and inference. A model without
+// a configuration uses DefaultConfig.
+type Config struct {
+	// InhibitionExponent raises the relative activation of an inhibiting
+	// combination before it is subtracted in multi-layer models.
+	InhibitionExponent float64
+	// DistanceExponent raises the distance of an object value from the ends of
+	// a range in formulas 7.18 and 7.19.
+	DistanceExponent float64
+	// CombinationExponent raises the share of an object left to a new
+	// combination in formula 7.20.
+	CombinationExponent float64
+	// WeedTolerance is how many features fewer than all an object of another
+	// class may share with a combination before an expansion is rejected.
+	WeedTolerance int
+	// ActivationScale multiplies the width of a range that the activation
+	// outside of it falls off over.
+	ActivationScale float64
+	// Membership computes range activations, GaussianTail when nil.
+	Membership MembershipFunc
+	// FeatureMembership overrides Membership for features by name.
+	FeatureMembership map[string]MembershipFunc
+	// EstimateShare is the share of the winning activation a target interval
+	// needs to contribute to a regression estimate. Averaging over all
+	// activated intervals pulls estimates to the target mean.
+	EstimateShare float64
+}
+
+// DefaultConfig returns the values of the ASONN paper.
+func DefaultConfig() Config {
+	return Config{
+		InhibitionExponent:  5,
+		DistanceExponent:    2,
+		CombinationExponent: 2,
+		WeedTolerance:       1,
+		ActivationScale:     1,
+		EstimateShare:       0.9,
+	}
+}
+
+// WithConfig sets the hyperparameters, DefaultConfig by default. The
+// configuration is saved with the model and used by Learn.
+func WithConfig(config Config) Option {
+	return func(o *options) {
+		o.config = config
+	}
+}
+
+func (config Config) validate() error {
+	exponents := []struct {
+		name  string
+		value float64
+	}{
+		{"InhibitionExponent", config.InhibitionExponent},
+		{"DistanceExponent", config.DistanceExponent},
+		{"CombinationExponent", config.CombinationExponent},
+	}
+	for _, exponent := range exponents {
+		if math.IsNaN(exponent.value) || math.IsInf(exponent.value, 0) {
+			return fmt.Errorf("%w: %s is %v", ErrInvalidConfig, exponent.name, exponent.value)
+		}
+	}
+	if config.WeedTolerance < 0 {
+		return fmt.Errorf("%w: WeedTolerance is %d", ErrInvalidConfig, config.WeedTolerance)
+	}
+	if !(config.ActivationScale > 0) || math.IsInf(config.ActivationScale, 0) {
+		return fmt.Errorf("%w: ActivationScale is %v", ErrInvalidConfig, config.ActivationScale)
+	}
+	if !(config.EstimateShare > 0 && config.EstimateShare <= 1) {
+		return fmt.Errorf("%w: EstimateShare is %v", ErrInvalidConfig, config.EstimateShare)
+	}
+	return nil
+}
+
+// WithMembership sets the membership function of all features without one
+// set by WithFeatureMembership.
+func WithMembership(membership MembershipFunc) Option {
+	return func(o *options) {
+		o.config.Membership = membership
+	}
+}
+
+// WithFeatureMembership sets the membership function of a feature.
+func WithFeatureMembership(feature string, membership MembershipFunc) Option {
+	return func(o *options) {
+		memberships := make(map[string]MembershipFunc)
+		for name, other := range o.config.FeatureMembership {
+			memberships[name] = other
+		}
+		memberships[feature] = membership
+		o.config.FeatureMembership = memberships
+	}
+}
+
+func (asonn *Asonn) config() Config {
+	if asonn.Config == nil {
+		return DefaultConfig()
+	}
+	return *asonn.Config
+}
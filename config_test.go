@@ -0,0 +1,65 @@
+package gasonn
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.ActivationScale = 3
+	config.InhibitionExponent = 2
+	asonn, err := Train(overlappingX, overlappingY, WithStrategy(MultiLayer), WithConfig(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults, err := Train(overlappingX, overlappingY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := [][]string{overlappingX[0], {"5", "5"}}
+	if got, want := asonn.Predict(test)[0], defaults.Predict(test)[0]; got <= want {
+		t.Errorf("Activation %f with a wider fall-off is not above %f", got, want)
+	}
+	var buffer bytes.Buffer
+	if err := asonn.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.config(), config) {
+		t.Errorf("Loaded configuration %+v instead of %+v", loaded.config(), config)
+	}
+	if !reflect.DeepEqual((&Asonn{}).config(), DefaultConfig()) {
+		t.Errorf("Model without configuration does not use the defaults")
+	}
+}
+
+type configErrorTestData struct {
+	name   string
+	change func(*Config)
+}
+
+var configErrorTests = []configErrorTestData{
+	{"NaN exponent", func(config *Config) { config.InhibitionExponent = math.NaN() }},
+	{"infinite exponent", func(config *Config) { config.DistanceExponent = math.Inf(1) }},
+	{"negative tolerance", func(config *Config) { config.WeedTolerance = -1 }},
+	{"zero scale", func(config *Config) { config.ActivationScale = 0 }},
+	{"zero share", func(config *Config) { config.EstimateShare = 0 }},
+	{"share above one", func(config *Config) { config.EstimateShare = 1.5 }},
+}
+
+func TestConfigErrors(t *testing.T) {
+	for _, testData := range configErrorTests {
+		config := DefaultConfig()
+		testData.change(&config)
+		if _, err := Train(trainX, trainY, WithConfig(config)); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: got error %v instead of %v", testData.name, err, ErrInvalidConfig)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Package crossval runs k-fold cross-validation of gasonn models over data in
+// the header-row layout used by gasonn.Train.
+package crossval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/jakubkosno/gasonn"
+	"github.com/jakubkosno/gasonn/eval"
+)
+
+var ErrTooFewRows = errors.New("Fewer labelled rows than folds")
+
+// Fold holds indices of rows of x used for training and testing. Index 0 is
+// the header row and never appears in a fold.
+type Fold struct {
+	Train []int
+	Test  []int
+}
+
+// StratifiedKFold splits labelled rows into k folds keeping class proportions.
+func StratifiedKFold(y []string, k int, seed int64) ([]Fold, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("Need at least 2 folds, got %d", k)
+	}
+	byClass := make(map[string][]int)
+	var classes []string
+	labelled := 0
+	for i := 1; i < len(y); i++ {
+		if y[i] == "" {
+			continue
+		}
+		if _, ok := byClass[y[i]]; !ok {
+			classes = append(classes, y[i])
+		}
+		byClass[y[i]] = append(byClass[y[i]], i)
+		labelled++
+	}
+	if labelled < k {
+		return nil, fmt.Errorf("%w: %d rows, %d folds", ErrTooFewRows, labelled, k)
+	}
+	sort.Strings(classes)
+	random := rand.New(rand.NewSource(seed))
+	tests := make([][]int, k)
+	next := 0
+	for _, class := range classes {
+		rows := byClass[class]
+		random.Shuffle(len(rows), func(i, j int) {
+			rows[i], rows[j] = rows[j], rows[i]
+		})
+		for _, row := range rows {
+			tests[next%k] = append(tests[next%k], row)
+			next++
+		}
+	}
+	return foldsFromTests(tests), nil
+}
+
+// RepeatedKFold runs StratifiedKFold repeats times with different shuffles.
+func RepeatedKFold(y []string, k int, repeats int, seed int64) ([]Fold, error) {
+	var folds []Fold
+	for i := 0; i < repeats; i++ {
+		repeat, err := StratifiedKFold(y, k, seed+int64(i))
+		if err != nil {
+			return nil, err
+		}
+		folds = append(folds, repeat...)
+	}
+	return folds, nil
+}
+
+// KFold splits labelled rows into k shuffled folds, for continuous targets
+// that cannot be stratified.
+func KFold(y []string, k int, seed int64) ([]Fold, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("Need at least 2 folds, got %d", k)
+	}
+	var rows []int
+	for i := 1; i < len(y); i++ {
+		if y[i] != "" {
+			rows = append(rows, i)
+		}
+	}
+	if len(rows) < k {
+		return nil, fmt.Errorf("%w: %d rows, %d folds", ErrTooFewRows, len(rows), k)
+	}
+	random := rand.New(rand.NewSource(seed))
+	random.Shuffle(len(rows), func(i, j int) {
+		rows[i], rows[j] = rows[j], rows[i]
+	})
+	tests := make([][]int, k)
+	for i, row := range rows {
+		tests[i%k] = append(tests[i%k], row)
+	}
+	return foldsFromTests(tests), nil
+}
+
+// LeaveOneOut returns one fold per labelled row.
+func LeaveOneOut(y []string) []Fold {
+	var tests [][]int
+	for i := 1; i < len(y); i++ {
+		if y[i] != "" {
+			tests = append(tests, []int{i})
+		}
+	}
+	return foldsFromTests(tests)
+}
+
+func foldsFromTests(tests [][]int) []Fold {
+	folds := make([]Fold, len(tests))
+	for i := range tests {
+		sort.Ints(tests[i])
+		folds[i].Test = tests[i]
+		for j := range tests {
+			if j != i {
+				folds[i].Train = append(folds[i].Train, tests[j]...)
+			}
+		}
+		sort.Ints(folds[i].Train)
+	}
+	return folds
+}
+
+// Split returns the training and test parts of a fold, each starting with the header row.
+func Split(x [][]string, y []string, fold Fold) ([][]string, []string, [][]string, []string) {
+	xTrain, yTrain := selectRows(x, y, fold.Train)
+	xTest, yTest := selectRows(x, y, fold.Test)
+	return xTrain, yTrain, xTest, yTest
+}
+
+func selectRows(x [][]string, y []string, rows []int) ([][]string, []string) {
+	selectedX := [][]string{x[0]}
+	selectedY := []string{y[0]}
+	for _, row := range rows {
+		selectedX = append(selectedX, x[row])
+		selectedY = append(selectedY, y[row])
+	}
+	return selectedX, selectedY
+}
+
+// Summary is the mean and sample standard deviation of a metric over folds.
+type Summary struct {
+	Mean float64
+	Std  float64
+}
+
+type Result struct {
+	Reports []*eval.Report
+	// Pooled evaluates out-of-fold predictions of all folds together, which
+	// is the meaningful report for leave-one-out.
+	Pooled           *eval.Report
+	Accuracy         Summary
+	BalancedAccuracy Summary
+	Kappa            Summary
+	MacroF1          Summary
+	WeightedF1       Summary
+}
+
+// Run trains a model with opts on every fold and evaluates it on the held-out rows.
+func Run(x [][]string, y []string, folds []Fold, opts ...gasonn.Option) (*Result, error) {
+	if len(folds) == 0 {
+		return nil, errors.New("No folds")
+	}
+	result := &Result{}
+	var allTrue, allPredicted []string
+	for i, fold := range folds {
+		xTrain, yTrain, xTest, yTest := Split(x, y, fold)
+		asonn, err := gasonn.Train(xTrain, yTrain, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("Fold %d: %w", i, err)
+		}
+		predictions, err := asonn.Classify(xTest)
+		if err != nil {
+			return nil, fmt.Errorf("Fold %d: %w", i, err)
+		}
+		var predicted []string
+		for _, prediction := range predictions {
+			predicted = append(predicted, prediction.Label)
+		}
+		report, err := eval.Evaluate(yTest[1:], predicted)
+		if err != nil {
+			return nil, fmt.Errorf("Fold %d: %w", i, err)
+		}
+		result.Reports = append(result.Reports, report)
+		allTrue = append(allTrue, yTest[1:]...)
+		allPredicted = append(allPredicted, predicted...)
+	}
+	pooled, err := eval.Evaluate(allTrue, allPredicted)
+	if err != nil {
+		return nil, err
+	}
+	result.Pooled = pooled
+	result.Accuracy = summarize(result.Reports, func(r *eval.Report) float64 { return r.Accuracy })
+	result.BalancedAccuracy = summarize(result.Reports, func(r *eval.Report) float64 { return r.BalancedAccuracy })
+	result.Kappa = summarize(result.Reports, func(r *eval.Report) float64 { return r.Kappa })
+	result.MacroF1 = summarize(result.Reports, func(r *eval.Report) float64 { return r.Macro.F1 })
+	result.WeightedF1 = summarize(result.Reports, func(r *eval.Report) float64 { return r.Weighted.F1 })
+	return result, nil
+}
+
+type RegressionResult struct {
+	Reports []*eval.RegressionReport
+	// Pooled evaluates out-of-fold estimates of all folds together.
+	Pooled *eval.RegressionReport
+	MAE    Summary
+	RMSE   Summary
+	R2     Summary
+}
+
+// RunRegression trains a regression model with opts on every fold and
+// evaluates its estimates and target intervals on the held-out rows.
+func RunRegression(x [][]string, y []string, folds []Fold, opts ...gasonn.Option) (*RegressionResult, error) {
+	if len(folds) == 0 {
+		return nil, errors.New("No folds")
+	}
+	result := &RegressionResult{}
+	var allTrue, allEstimated, allLow, allHigh []float64
+	for i, fold := range folds {
+		xTrain, yTrain, xTest, yTest := Split(x, y, fold)
+		asonn, err := gasonn.TrainRegression(xTrain, yTrain, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("Fold %d: %w", i, err)
+		}
+		estimates, err := asonn.Regress(xTest)
+		if err != nil {
+			return nil, fmt.Errorf("Fold %d: %w", i, err)
+		}
+		var targets, estimated, low, high []float64
+		for j, estimate := range estimates {
+			target, err := strconv.ParseFloat(yTest[j+1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Fold %d: %w", i, err)
+			}
+			targets = append(targets, target)
+			estimated = append(estimated, estimate.Value)
+			low = append(low, estimate.Low)
+			high = append(high, estimate.High)
+		}
+		report, err := eval.EvaluateIntervals(targets, estimated, low, high)
+		if err != nil {
+			return nil, fmt.Errorf("Fold %d: %w", i, err)
+		}
+		result.Reports = append(result.Reports, report)
+		allTrue = append(allTrue, targets...)
+		allEstimated = append(allEstimated, estimated...)
+		allLow = append(allLow, low...)
+		allHigh = append(allHigh, high...)
+	}
+	pooled, err := eval.EvaluateIntervals(allTrue, allEstimated, allLow, allHigh)
+	if err != nil {
+		return nil, err
+	}
+	result.Pooled = pooled
+	result.MAE = summarize(result.Reports, func(r *eval.RegressionReport) float64 { return r.MAE })
+	result.RMSE = summarize(result.Reports, func(r *eval.RegressionReport) float64 { return r.RMSE })
+	result.R2 = summarize(result.Reports, func(r *eval.RegressionReport) float64 { return r.R2 })
+	return result, nil
+}
+
+func summarize[R any](reports []R, metric func(R) float64) Summary {
+	mean := 0.0
+	for _, report := range reports {
+		mean += metric(report)
+	}
+	mean /= float64(len(reports))
+	if len(reports) < 2 {
+		return Summary{Mean: mean}
+	}
+	variance := 0.0
+	for _, report := range reports {
+		variance += math.Pow(metric(report)-mean, 2)
+	}
+	return Summary{Mean: mean, Std: math.Sqrt(variance / float64(len(reports)-1))}
+}
@@ -0,0 +1,132 @@
+package crossval
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/jakubkosno/gasonn"
+)
+
+var x = [][]string{
+	{"a", "b"},
+	{"1.0", "2.5"},
+	{"1.2", "2.7"},
+	{"3.1", "0.5"},
+	{"3.3", "0.7"},
+	{"1.1", "2.6"},
+	{"3.0", "0.4"},
+	{"1.3", "2.4"},
+	{"3.2", "0.6"},
+}
+
+var y = []string{"target", "p", "p", "n", "n", "p", "n", "p", "n"}
+
+func TestStratifiedKFold(t *testing.T) {
+	folds, err := StratifiedKFold(y, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fold := range folds {
+		if len(fold.Train)+len(fold.Test) != len(y)-1 {
+			t.Errorf("Fold doesn't cover all rows")
+		}
+		counts := make(map[string]int)
+		for _, row := range fold.Test {
+			if row == 0 {
+				t.Errorf("Header row in fold")
+			}
+			counts[y[row]]++
+		}
+		if counts["p"] != 2 || counts["n"] != 2 {
+			t.Errorf("Fold not stratified: %v", counts)
+		}
+	}
+	if _, err := StratifiedKFold(y, 9, 1); !errors.Is(err, ErrTooFewRows) {
+		t.Errorf("Got error %v instead of %v", err, ErrTooFewRows)
+	}
+}
+
+func TestRun(t *testing.T) {
+	folds, err := RepeatedKFold(y, 2, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, strategy := range []gasonn.Strategy{gasonn.SingleLayer, gasonn.MultiLayer} {
+		result, err := Run(x, y, folds, gasonn.WithStrategy(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Reports) != 4 {
+			t.Errorf("Got %d reports instead of 4", len(result.Reports))
+		}
+		mean := 0.0
+		for _, report := range result.Reports {
+			mean += report.Accuracy / float64(len(result.Reports))
+		}
+		if math.Abs(result.Accuracy.Mean-mean) > 1e-9 || result.Accuracy.Std < 0 {
+			t.Errorf("Strategy %d: invalid accuracy summary %v", strategy, result.Accuracy)
+		}
+		if strategy == gasonn.MultiLayer && result.Accuracy.Mean != 1 {
+			t.Errorf("Accuracy %v on separable data", result.Accuracy)
+		}
+	}
+}
+
+func TestLeaveOneOut(t *testing.T) {
+	folds := LeaveOneOut(y)
+	if len(folds) != len(y)-1 {
+		t.Fatalf("Got %d folds instead of %d", len(folds), len(y)-1)
+	}
+	result, err := Run(x, y, folds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Pooled.Labels) != 2 {
+		t.Errorf("Pooled report has labels %v", result.Pooled.Labels)
+	}
+}
+
+var targets = []string{"target", "1.0", "1.5", "7.0", "8.0", "1.2", "7.5", "1.4", "7.2"}
+
+func TestKFold(t *testing.T) {
+	folds, err := KFold(targets, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int]bool)
+	for _, fold := range folds {
+		if len(fold.Train)+len(fold.Test) != len(targets)-1 {
+			t.Errorf("Fold doesn't cover all rows")
+		}
+		for _, row := range fold.Test {
+			if row == 0 || seen[row] {
+				t.Errorf("Row %d tested twice or header row in fold", row)
+			}
+			seen[row] = true
+		}
+	}
+	if len(seen) != len(targets)-1 {
+		t.Errorf("Tested %d rows instead of %d", len(seen), len(targets)-1)
+	}
+	if _, err := KFold(targets, 9, 1); !errors.Is(err, ErrTooFewRows) {
+		t.Errorf("Got error %v instead of %v", err, ErrTooFewRows)
+	}
+}
+
+func TestRunRegression(t *testing.T) {
+	folds := LeaveOneOut(targets)
+	result, err := RunRegression(x, targets, folds, gasonn.WithStrategy(gasonn.MultiLayer), gasonn.WithTargetIntervals(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Reports) != len(folds) || result.Pooled.N != len(targets)-1 {
+		t.Fatalf("Got %d reports of %d rows", len(result.Reports), result.Pooled.N)
+	}
+	if result.Pooled.R2 < 0.9 {
+		t.Errorf("R2 %v on separable data", result.Pooled.R2)
+	}
+	if _, err := RunRegression(x, y, folds); !errors.Is(err, gasonn.ErrInvalidValue) {
+		t.Errorf("Got error %v instead of %v", err, gasonn.ErrInvalidValue)
+	}
+}
@@ -0,0 +1,218 @@
+// Package dataset loads labelled tables in the layout used by gasonn.Train:
+// feature rows and labels that both start with a header.
+package dataset
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jakubkosno/pmlb"
+)
+
+var ErrNotFound = errors.New("Dataset not found")
+
+// Source loads datasets by name.
+type Source interface {
+	Load(name string) ([][]string, []string, error)
+}
+
+// PMLBCache reads datasets stored like the PMLB repository,
+// as Dir/<name>/<name>.tsv.gz with the label in the last column.
+type PMLBCache struct {
+	Dir string
+}
+
+func (cache PMLBCache) path(name string) string {
+	return filepath.Join(cache.Dir, name, name+".tsv.gz")
+}
+
+func (cache PMLBCache) Load(name string) ([][]string, []string, error) {
+	file, err := os.Open(cache.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%w: %s in %s", ErrNotFound, name, cache.Dir)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", cache.path(name), err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", cache.path(name), err)
+	}
+	var table [][]string
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		table = append(table, strings.Split(line, "\t"))
+	}
+	if len(table) == 0 || len(table[0]) == 0 {
+		return nil, nil, fmt.Errorf("%s: empty dataset", cache.path(name))
+	}
+	return SplitTarget(table, table[0][len(table[0])-1])
+}
+
+// Store writes a dataset into the cache so it can be loaded offline later.
+func (cache PMLBCache) Store(name string, x [][]string, y []string) error {
+	if len(x) != len(y) {
+		return fmt.Errorf("%d rows, %d labels", len(x), len(y))
+	}
+	if err := os.MkdirAll(filepath.Dir(cache.path(name)), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(cache.path(name))
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(file)
+	for i := range x {
+		if len(x[i]) == 0 && y[i] == "" {
+			continue
+		}
+		if _, err := io.WriteString(writer, strings.Join(append(append([]string{}, x[i]...), y[i]), "\t")+"\n"); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Files reads Dir/<name>.csv or Dir/<name>.tsv, or <name> itself when it has
+// one of those extensions. Target names the label column, the last column
+// when empty.
+type Files struct {
+	Dir    string
+	Target string
+}
+
+func (files Files) Load(name string) ([][]string, []string, error) {
+	var candidates []string
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".tsv":
+		candidates = []string{filepath.Join(files.Dir, name)}
+	default:
+		candidates = []string{filepath.Join(files.Dir, name+".csv"), filepath.Join(files.Dir, name+".tsv")}
+	}
+	for _, path := range candidates {
+		table, err := ReadTable(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		target := files.Target
+		if target == "" {
+			target = table[0][len(table[0])-1]
+		}
+		return SplitTarget(table, target)
+	}
+	return nil, nil, fmt.Errorf("%w: %s in %s", ErrNotFound, name, files.Dir)
+}
+
+// Table is an in-memory dataset.
+type Table struct {
+	X [][]string
+	Y []string
+}
+
+// Memory serves tables held in memory.
+type Memory map[string]Table
+
+func (memory Memory) Load(name string) ([][]string, []string, error) {
+	table, ok := memory[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return table.X, table.Y, nil
+}
+
+// Online downloads datasets from the PMLB repository.
+type Online struct{}
+
+func (Online) Load(name string) ([][]string, []string, error) {
+	x, y, err := pmlb.FetchXYData(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if x == nil {
+		return nil, nil, fmt.Errorf("%w: %s in PMLB", ErrNotFound, name)
+	}
+	return x, y, nil
+}
+
+// Chain tries its sources in order and returns the first dataset found.
+type Chain []Source
+
+func (chain Chain) Load(name string) ([][]string, []string, error) {
+	for _, source := range chain {
+		x, y, err := source.Load(name)
+		if !errors.Is(err, ErrNotFound) {
+			return x, y, err
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// ReadTable reads a CSV file, or a TSV file when the name ends with .tsv.
+func ReadTable(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+	}
+	reader.FieldsPerRecord = -1
+	table, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(table) == 0 {
+		return nil, fmt.Errorf("%s: empty file", path)
+	}
+	return table, nil
+}
+
+// SplitTarget removes the target column from table and returns it separately,
+// header included.
+func SplitTarget(table [][]string, target string) ([][]string, []string, error) {
+	column := -1
+	for i, name := range table[0] {
+		if name == target {
+			column = i
+			break
+		}
+	}
+	if column < 0 {
+		return nil, nil, fmt.Errorf("No column %q", target)
+	}
+	x := make([][]string, 0, len(table))
+	y := make([]string, 0, len(table))
+	for i, row := range table {
+		if column >= len(row) {
+			return nil, nil, fmt.Errorf("Row %d has no column %q", i, target)
+		}
+		features := make([]string, 0, len(row)-1)
+		features = append(features, row[:column]...)
+		features = append(features, row[column+1:]...)
+		x = append(x, features)
+		y = append(y, row[column])
+	}
+	return x, y, nil
+}
@@ -0,0 +1,79 @@
+package dataset
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var x = [][]string{{"a", "b"}, {"1", "2"}, {"3", "4"}}
+var y = []string{"target", "p", "n"}
+
+func TestPMLBCache(t *testing.T) {
+	cache := PMLBCache{Dir: t.TempDir()}
+	if _, _, err := cache.Load("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Got error %v instead of %v", err, ErrNotFound)
+	}
+	if err := cache.Store("small", x, y); err != nil {
+		t.Fatal(err)
+	}
+	loadedX, loadedY, err := cache.Load("small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTable(t, loadedX, loadedY)
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "small.csv"), []byte("a,target,b\n1,p,2\n3,n,4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loadedX, loadedY, err := Files{Dir: dir, Target: "target"}.Load("small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTable(t, loadedX, loadedY)
+	if _, _, err := (Files{Dir: dir}).Load("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Got error %v instead of %v", err, ErrNotFound)
+	}
+}
+
+func TestChain(t *testing.T) {
+	chain := Chain{Memory{}, Memory{"small": {X: x, Y: y}}}
+	loadedX, loadedY, err := chain.Load("small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTable(t, loadedX, loadedY)
+	if _, _, err := chain.Load("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Got error %v instead of %v", err, ErrNotFound)
+	}
+}
+
+func TestSplitTarget(t *testing.T) {
+	splitX, splitY, err := SplitTarget([][]string{{"a", "class", "b"}, {"1", "p", "2"}}, "class")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(splitX[0], ",") != "a,b" || strings.Join(splitX[1], ",") != "1,2" || strings.Join(splitY, ",") != "class,p" {
+		t.Errorf("Invalid split %v %v", splitX, splitY)
+	}
+	if _, _, err := SplitTarget(x, "missing"); err == nil {
+		t.Errorf("Missing column not reported")
+	}
+}
+
+func checkTable(t *testing.T, loadedX [][]string, loadedY []string) {
+	t.Helper()
+	if len(loadedX) != len(x) || strings.Join(loadedY, ",") != strings.Join(y, ",") {
+		t.Fatalf("Loaded %v %v", loadedX, loadedY)
+	}
+	for i := range x {
+		if strings.Join(loadedX[i], ",") != strings.Join(x[i], ",") {
+			t.Errorf("Row %d is %v instead of %v", i, loadedX[i], x[i])
+		}
+	}
+}
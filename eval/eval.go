@@ -0,0 +1,304 @@
+// Package eval computes classification and regression metrics from true and
+// predicted values.
+package eval
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrEmpty          = errors.New("No labels to evaluate")
+	ErrLengthMismatch = errors.New("Number of true and predicted labels differs")
+)
+
+// ClassMetrics holds one-vs-rest metrics of a single class.
+type ClassMetrics struct {
+	Class     string   `json:"class"`
+	Precision float64  `json:"precision"`
+	Recall    float64  `json:"recall"`
+	F1        float64  `json:"f1"`
+	Support   int      `json:"support"`
+	ROCAUC    *float64 `json:"roc_auc,omitempty"`
+}
+
+type Average struct {
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+type Report struct {
+	Labels []string `json:"labels"`
+	// Confusion[i][j] counts objects of class Labels[i] predicted as Labels[j].
+	Confusion        [][]int        `json:"confusion"`
+	Classes          []ClassMetrics `json:"classes"`
+	Accuracy         float64        `json:"accuracy"`
+	BalancedAccuracy float64        `json:"balanced_accuracy"`
+	Kappa            float64        `json:"kappa"`
+	Macro            Average        `json:"macro"`
+	Micro            Average        `json:"micro"`
+	Weighted         Average        `json:"weighted"`
+	// LogLoss and ROCAUC are only set when scores are evaluated.
+	LogLoss *float64 `json:"log_loss,omitempty"`
+	ROCAUC  *float64 `json:"roc_auc,omitempty"`
+}
+
+// Evaluate compares predicted labels with true labels.
+func Evaluate(yTrue []string, yPred []string) (*Report, error) {
+	if len(yTrue) != len(yPred) {
+		return nil, fmt.Errorf("%w: %d true, %d predicted", ErrLengthMismatch, len(yTrue), len(yPred))
+	}
+	if len(yTrue) == 0 {
+		return nil, ErrEmpty
+	}
+	report := &Report{Labels: labels(yTrue, yPred)}
+	index := make(map[string]int)
+	for i, label := range report.Labels {
+		index[label] = i
+	}
+	report.Confusion = make([][]int, len(report.Labels))
+	for i := range report.Confusion {
+		report.Confusion[i] = make([]int, len(report.Labels))
+	}
+	for i := range yTrue {
+		report.Confusion[index[yTrue[i]]][index[yPred[i]]]++
+	}
+	report.calculate(len(yTrue))
+	return report, nil
+}
+
+// EvaluateScores additionally computes log-loss and one-vs-rest ROC-AUC from
+// per-row class probabilities, such as the output of PredictProba.
+func EvaluateScores(yTrue []string, yPred []string, scores []map[string]float64) (*Report, error) {
+	if len(scores) != len(yTrue) {
+		return nil, fmt.Errorf("%w: %d true, %d scores", ErrLengthMismatch, len(yTrue), len(scores))
+	}
+	report, err := Evaluate(yTrue, yPred)
+	if err != nil {
+		return nil, err
+	}
+	logLoss := 0.0
+	for i := range yTrue {
+		probability := math.Min(math.Max(scores[i][yTrue[i]], 1e-15), 1)
+		logLoss -= math.Log(probability)
+	}
+	logLoss /= float64(len(yTrue))
+	report.LogLoss = &logLoss
+	sum, count := 0.0, 0
+	for i := range report.Classes {
+		if auc, ok := rocAUC(yTrue, scores, report.Classes[i].Class); ok {
+			report.Classes[i].ROCAUC = &auc
+			sum += auc
+			count++
+		}
+	}
+	if count > 0 {
+		macro := sum / float64(count)
+		report.ROCAUC = &macro
+	}
+	return report, nil
+}
+
+func labels(yTrue []string, yPred []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, values := range [][]string{yTrue, yPred} {
+		for _, value := range values {
+			if !seen[value] {
+				seen[value] = true
+				result = append(result, value)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (report *Report) calculate(n int) {
+	correct := 0
+	expected := 0.0
+	recallSum, supported := 0.0, 0
+	for i, label := range report.Labels {
+		truePositives := report.Confusion[i][i]
+		support, predicted := 0, 0
+		for j := range report.Labels {
+			support += report.Confusion[i][j]
+			predicted += report.Confusion[j][i]
+		}
+		metrics := ClassMetrics{Class: label, Support: support}
+		metrics.Precision = divide(float64(truePositives), float64(predicted))
+		metrics.Recall = divide(float64(truePositives), float64(support))
+		metrics.F1 = divide(2*metrics.Precision*metrics.Recall, metrics.Precision+metrics.Recall)
+		report.Classes = append(report.Classes, metrics)
+		correct += truePositives
+		expected += float64(support) * float64(predicted) / float64(n) / float64(n)
+		if support > 0 {
+			recallSum += metrics.Recall
+			supported++
+		}
+		report.Macro.Precision += metrics.Precision / float64(len(report.Labels))
+		report.Macro.Recall += metrics.Recall / float64(len(report.Labels))
+		report.Macro.F1 += metrics.F1 / float64(len(report.Labels))
+		share := float64(support) / float64(n)
+		report.Weighted.Precision += metrics.Precision * share
+		report.Weighted.Recall += metrics.Recall * share
+		report.Weighted.F1 += metrics.F1 * share
+	}
+	report.Accuracy = float64(correct) / float64(n)
+	report.Micro = Average{Precision: report.Accuracy, Recall: report.Accuracy, F1: report.Accuracy}
+	report.BalancedAccuracy = divide(recallSum, float64(supported))
+	if expected < 1 {
+		report.Kappa = (report.Accuracy - expected) / (1 - expected)
+	} else {
+		report.Kappa = 1
+	}
+}
+
+func divide(numerator float64, denominator float64) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
+
+// rocAUC computes the area under the ROC curve of class against the rest with
+// the Mann-Whitney statistic, averaging ranks of tied scores.
+func rocAUC(yTrue []string, scores []map[string]float64, class string) (float64, bool) {
+	order := make([]int, len(yTrue))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return scores[order[i]][class] < scores[order[j]][class]
+	})
+	positives, negatives := 0.0, 0.0
+	rankSum := 0.0
+	for i := 0; i < len(order); {
+		j := i
+		for j < len(order) && scores[order[j]][class] == scores[order[i]][class] {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if yTrue[order[k]] == class {
+				positives++
+				rankSum += rank
+			} else {
+				negatives++
+			}
+		}
+		i = j
+	}
+	if positives == 0 || negatives == 0 {
+		return 0, false
+	}
+	return (rankSum - positives*(positives+1)/2) / (positives * negatives), true
+}
+
+// WriteText renders the confusion matrix, per-class metrics and summary as text tables.
+func (report *Report) WriteText(w io.Writer) error {
+	var builder strings.Builder
+	width := 9
+	for _, label := range report.Labels {
+		if len(label)+1 > width {
+			width = len(label) + 1
+		}
+	}
+	fmt.Fprintf(&builder, "%-*s", width, "true\\pred")
+	for _, label := range report.Labels {
+		fmt.Fprintf(&builder, " %*s", width, label)
+	}
+	builder.WriteString("\n")
+	for i, label := range report.Labels {
+		fmt.Fprintf(&builder, "%-*s", width, label)
+		for j := range report.Labels {
+			fmt.Fprintf(&builder, " %*d", width, report.Confusion[i][j])
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n")
+	fmt.Fprintf(&builder, "%-*s %9s %9s %9s %9s %9s\n", width, "class", "precision", "recall", "f1", "support", "roc_auc")
+	for _, metrics := range report.Classes {
+		auc := "-"
+		if metrics.ROCAUC != nil {
+			auc = strconv.FormatFloat(*metrics.ROCAUC, 'f', 4, 64)
+		}
+		fmt.Fprintf(&builder, "%-*s %9.4f %9.4f %9.4f %9d %9s\n", width, metrics.Class, metrics.Precision, metrics.Recall, metrics.F1, metrics.Support, auc)
+	}
+	for _, average := range report.averages() {
+		fmt.Fprintf(&builder, "%-*s %9.4f %9.4f %9.4f\n", width, average.name, average.Precision, average.Recall, average.F1)
+	}
+	builder.WriteString("\n")
+	for _, summary := range report.summary() {
+		fmt.Fprintf(&builder, "%-18s %.4f\n", summary.name, summary.value)
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// WriteCSV writes one row per class and average followed by summary metrics.
+func (report *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{{"class", "precision", "recall", "f1", "support", "roc_auc"}}
+	for _, metrics := range report.Classes {
+		auc := ""
+		if metrics.ROCAUC != nil {
+			auc = formatFloat(*metrics.ROCAUC)
+		}
+		records = append(records, []string{metrics.Class, formatFloat(metrics.Precision), formatFloat(metrics.Recall), formatFloat(metrics.F1), strconv.Itoa(metrics.Support), auc})
+	}
+	for _, average := range report.averages() {
+		records = append(records, []string{average.name, formatFloat(average.Precision), formatFloat(average.Recall), formatFloat(average.F1), "", ""})
+	}
+	for _, summary := range report.summary() {
+		records = append(records, []string{summary.name, formatFloat(summary.value), "", "", "", ""})
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (report *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+type namedAverage struct {
+	Average
+	name string
+}
+
+func (report *Report) averages() []namedAverage {
+	return []namedAverage{{report.Macro, "macro avg"}, {report.Micro, "micro avg"}, {report.Weighted, "weighted avg"}}
+}
+
+type namedValue struct {
+	name  string
+	value float64
+}
+
+func (report *Report) summary() []namedValue {
+	summary := []namedValue{{"accuracy", report.Accuracy}, {"balanced_accuracy", report.BalancedAccuracy}, {"kappa", report.Kappa}}
+	if report.LogLoss != nil {
+		summary = append(summary, namedValue{"log_loss", *report.LogLoss})
+	}
+	if report.ROCAUC != nil {
+		summary = append(summary, namedValue{"roc_auc", *report.ROCAUC})
+	}
+	return summary
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
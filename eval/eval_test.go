@@ -0,0 +1,103 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+var yTrue = []string{"a", "a", "a", "b", "b", "c"}
+var yPred = []string{"a", "a", "b", "b", "c", "c"}
+
+func TestEvaluate(t *testing.T) {
+	report, err := Evaluate(yTrue, yPred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedConfusion := [][]int{{2, 1, 0}, {0, 1, 1}, {0, 0, 1}}
+	for i := range expectedConfusion {
+		for j := range expectedConfusion[i] {
+			if report.Confusion[i][j] != expectedConfusion[i][j] {
+				t.Errorf("Confusion[%d][%d] is %d instead of %d", i, j, report.Confusion[i][j], expectedConfusion[i][j])
+			}
+		}
+	}
+	checkClose(t, "accuracy", report.Accuracy, 4.0/6)
+	checkClose(t, "precision of a", report.Classes[0].Precision, 1)
+	checkClose(t, "recall of a", report.Classes[0].Recall, 2.0/3)
+	checkClose(t, "f1 of b", report.Classes[1].F1, 0.5)
+	checkClose(t, "balanced accuracy", report.BalancedAccuracy, (2.0/3+0.5+1)/3)
+	checkClose(t, "micro f1", report.Micro.F1, report.Accuracy)
+	// po = 4/6, pe = (3*2 + 2*2 + 1*2) / 36 = 1/3
+	checkClose(t, "kappa", report.Kappa, (4.0/6-1.0/3)/(1-1.0/3))
+}
+
+func TestEvaluateScores(t *testing.T) {
+	scores := []map[string]float64{
+		{"a": 0.8, "b": 0.1, "c": 0.1},
+		{"a": 0.6, "b": 0.3, "c": 0.1},
+		{"a": 0.4, "b": 0.5, "c": 0.1},
+		{"a": 0.2, "b": 0.7, "c": 0.1},
+		{"a": 0.1, "b": 0.4, "c": 0.5},
+		{"a": 0.1, "b": 0.1, "c": 0.8},
+	}
+	report, err := EvaluateScores(yTrue, yPred, scores)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, "roc auc of a", *report.Classes[0].ROCAUC, 1)
+	expectedLogLoss := -(math.Log(0.8) + math.Log(0.6) + math.Log(0.4) + math.Log(0.7) + math.Log(0.4) + math.Log(0.8)) / 6
+	checkClose(t, "log loss", *report.LogLoss, expectedLogLoss)
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	if _, err := Evaluate(nil, nil); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Got error %v instead of %v", err, ErrEmpty)
+	}
+	if _, err := Evaluate(yTrue, yPred[1:]); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("Got error %v instead of %v", err, ErrLengthMismatch)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	report, err := Evaluate(yTrue, yPred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err := report.WriteCSV(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1+3+3+3 {
+		t.Errorf("Wrote %d CSV records", len(records))
+	}
+	buffer.Reset()
+	if err := report.WriteJSON(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Accuracy != report.Accuracy {
+		t.Errorf("Accuracy not written to JSON")
+	}
+	buffer.Reset()
+	if err := report.WriteText(&buffer); err != nil || buffer.Len() == 0 {
+		t.Errorf("Text report not written: %v", err)
+	}
+}
+
+func checkClose(t *testing.T, name string, got float64, expected float64) {
+	t.Helper()
+	if math.Abs(got-expected) > 1e-9 {
+		t.Errorf("%s is %f instead of %f", name, got, expected)
+	}
+}
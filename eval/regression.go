@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type RegressionReport struct {
+	N    int     `json:"n"`
+	MAE  float64 `json:"mae"`
+	RMSE float64 `json:"rmse"`
+	// R2 is 1 minus the squared error over the variance of the true values,
+	// or 0 for constant true values that are not predicted exactly.
+	R2 float64 `json:"r2"`
+	// Coverage is the share of true values within predicted intervals, only
+	// set by EvaluateIntervals.
+	Coverage *float64 `json:"coverage,omitempty"`
+}
+
+// EvaluateRegression compares predicted values with true values.
+func EvaluateRegression(yTrue []float64, yPred []float64) (*RegressionReport, error) {
+	if len(yTrue) != len(yPred) {
+		return nil, fmt.Errorf("%w: %d true, %d predicted", ErrLengthMismatch, len(yTrue), len(yPred))
+	}
+	if len(yTrue) == 0 {
+		return nil, ErrEmpty
+	}
+	n := float64(len(yTrue))
+	mean := 0.0
+	for _, value := range yTrue {
+		mean += value / n
+	}
+	report := &RegressionReport{N: len(yTrue)}
+	squared, variance := 0.0, 0.0
+	for i := range yTrue {
+		report.MAE += math.Abs(yTrue[i]-yPred[i]) / n
+		squared += math.Pow(yTrue[i]-yPred[i], 2)
+		variance += math.Pow(yTrue[i]-mean, 2)
+	}
+	report.RMSE = math.Sqrt(squared / n)
+	switch {
+	case variance > 0:
+		report.R2 = 1 - squared/variance
+	case squared == 0:
+		report.R2 = 1
+	}
+	return report, nil
+}
+
+// EvaluateIntervals additionally computes how many true values fall within
+// predicted [low, high] intervals, such as the intervals of Regress.
+func EvaluateIntervals(yTrue []float64, yPred []float64, low []float64, high []float64) (*RegressionReport, error) {
+	if len(low) != len(yTrue) || len(high) != len(yTrue) {
+		return nil, fmt.Errorf("%w: %d true, %d intervals", ErrLengthMismatch, len(yTrue), len(low))
+	}
+	report, err := EvaluateRegression(yTrue, yPred)
+	if err != nil {
+		return nil, err
+	}
+	covered := 0
+	for i := range yTrue {
+		if low[i] <= yTrue[i] && yTrue[i] <= high[i] {
+			covered++
+		}
+	}
+	coverage := float64(covered) / float64(len(yTrue))
+	report.Coverage = &coverage
+	return report, nil
+}
+
+// WriteText renders the metrics as a text table.
+func (report *RegressionReport) WriteText(w io.Writer) error {
+	var builder strings.Builder
+	for _, summary := range report.summary() {
+		fmt.Fprintf(&builder, "%-18s %.4f\n", summary.name, summary.value)
+	}
+	fmt.Fprintf(&builder, "%-18s %d\n", "n", report.N)
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// WriteCSV writes one row per metric.
+func (report *RegressionReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{{"metric", "value"}}
+	for _, summary := range report.summary() {
+		records = append(records, []string{summary.name, formatFloat(summary.value)})
+	}
+	records = append(records, []string{"n", strconv.Itoa(report.N)})
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (report *RegressionReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func (report *RegressionReport) summary() []namedValue {
+	summary := []namedValue{{"mae", report.MAE}, {"rmse", report.RMSE}, {"r2", report.R2}}
+	if report.Coverage != nil {
+		summary = append(summary, namedValue{"coverage", *report.Coverage})
+	}
+	return summary
+}
@@ -0,0 +1,85 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+var valuesTrue = []float64{1, 2, 3, 4}
+var valuesPred = []float64{1.5, 2, 2, 4}
+
+func TestEvaluateRegression(t *testing.T) {
+	report, err := EvaluateRegression(valuesTrue, valuesPred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, "mae", report.MAE, 1.5/4)
+	checkClose(t, "rmse", report.RMSE, math.Sqrt(1.25/4))
+	// The variance of the true values sums to 5.
+	checkClose(t, "r2", report.R2, 1-1.25/5)
+	if report.Coverage != nil {
+		t.Errorf("Coverage %v without intervals", *report.Coverage)
+	}
+	constant, err := EvaluateRegression([]float64{2, 2}, []float64{2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, "r2 of constant values", constant.R2, 1)
+}
+
+func TestEvaluateIntervals(t *testing.T) {
+	report, err := EvaluateIntervals(valuesTrue, valuesPred, []float64{1, 1, 2, 5}, []float64{2, 3, 2.5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, "coverage", *report.Coverage, 0.5)
+}
+
+func TestEvaluateRegressionErrors(t *testing.T) {
+	if _, err := EvaluateRegression(nil, nil); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Got error %v instead of %v", err, ErrEmpty)
+	}
+	if _, err := EvaluateRegression(valuesTrue, valuesPred[1:]); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("Got error %v instead of %v", err, ErrLengthMismatch)
+	}
+	if _, err := EvaluateIntervals(valuesTrue, valuesPred, valuesPred, nil); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("Got error %v instead of %v", err, ErrLengthMismatch)
+	}
+}
+
+func TestWriteRegression(t *testing.T) {
+	report, err := EvaluateIntervals(valuesTrue, valuesPred, valuesPred, valuesPred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err := report.WriteCSV(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1+4+1 {
+		t.Errorf("Wrote %d CSV records", len(records))
+	}
+	buffer.Reset()
+	if err := report.WriteJSON(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	var decoded RegressionReport
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.RMSE != report.RMSE || decoded.Coverage == nil {
+		t.Errorf("Metrics not written to JSON")
+	}
+	buffer.Reset()
+	if err := report.WriteText(&buffer); err != nil || buffer.Len() == 0 {
+		t.Errorf("Text report not written: %v", err)
+	}
+}
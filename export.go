@@ -0,0 +1,224 @@
+package gasonn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var nodeColors = map[string]string{
+	Feature:     "lightblue",
+	Value:       "lightgrey",
+	Object:      "khaki",
+	Class:       "salmon",
+	Range:       "palegreen",
+	Combination: "orange",
+	Target:      "plum",
+}
+
+type exportOptions struct {
+	class string
+	types map[string]bool
+}
+
+// ExportOption filters the nodes written by ExportDOT and ExportJSON.
+type ExportOption func(*exportOptions)
+
+// ExportClass keeps only nodes belonging to the class with the given label.
+// Feature and Target nodes are shared by all classes and are always kept.
+func ExportClass(label string) ExportOption {
+	return func(o *exportOptions) {
+		o.class = label
+	}
+}
+
+// ExportTypes keeps only nodes of the given types, e.g. ExportTypes(Combination, Range).
+func ExportTypes(types ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.types = make(map[string]bool)
+		for _, nodeType := range types {
+			o.types[nodeType] = true
+		}
+	}
+}
+
+type exportGraph struct {
+	nodes []*Node
+	ids   map[*Node]int
+	edges []exportEdge
+}
+
+type exportEdge struct {
+	from          int
+	to            int
+	weight        float64
+	reverseWeight float64
+	oneWay        bool
+}
+
+// ExportDOT writes the graph in Graphviz DOT format. Nodes are colored by type
+// and one-way inhibitory connections between combinations are dashed.
+func (asonn *Asonn) ExportDOT(w io.Writer, opts ...ExportOption) error {
+	graph := asonn.exportGraph(opts)
+	var builder strings.Builder
+	builder.WriteString("digraph asonn {\n")
+	builder.WriteString("\tnode [style=filled];\n")
+	for id, node := range graph.nodes {
+		fmt.Fprintf(&builder, "\tn%d [label=%s, fillcolor=%s, tooltip=%s];\n",
+			id, strconv.Quote(nodeLabel(node)), nodeColors[node.Type], strconv.Quote(node.Type))
+	}
+	for _, edge := range graph.edges {
+		if edge.oneWay {
+			fmt.Fprintf(&builder, "\tn%d -> n%d [label=%s, style=dashed];\n", edge.from, edge.to, strconv.Quote(formatWeight(edge.weight)))
+			continue
+		}
+		label := formatWeight(edge.weight)
+		if edge.reverseWeight != edge.weight {
+			label += " / " + formatWeight(edge.reverseWeight)
+		}
+		fmt.Fprintf(&builder, "\tn%d -> n%d [label=%s, dir=none];\n", edge.from, edge.to, strconv.Quote(label))
+	}
+	builder.WriteString("}\n")
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+type jsonGraph struct {
+	Directed bool       `json:"directed"`
+	Nodes    []jsonNode `json:"nodes"`
+	Links    []jsonLink `json:"links"`
+}
+
+type jsonNode struct {
+	ID    int    `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+	Class string `json:"class,omitempty"`
+}
+
+type jsonLink struct {
+	Source        int      `json:"source"`
+	Target        int      `json:"target"`
+	Weight        *float64 `json:"weight"`
+	ReverseWeight *float64 `json:"reverse_weight,omitempty"`
+	OneWay        bool     `json:"one_way"`
+}
+
+// ExportJSON writes the graph as a node-link JSON document. Weights that are
+// not finite are written as null.
+func (asonn *Asonn) ExportJSON(w io.Writer, opts ...ExportOption) error {
+	graph := asonn.exportGraph(opts)
+	document := jsonGraph{Directed: true, Nodes: []jsonNode{}, Links: []jsonLink{}}
+	for id, node := range graph.nodes {
+		jsonNode := jsonNode{ID: id, Type: node.Type, Label: nodeLabel(node)}
+		if node.Type == Combination || node.Type == Object {
+			jsonNode.Class = getClassOfObject(node)
+		}
+		document.Nodes = append(document.Nodes, jsonNode)
+	}
+	for _, edge := range graph.edges {
+		link := jsonLink{Source: edge.from, Target: edge.to, Weight: finiteOrNil(edge.weight), OneWay: edge.oneWay}
+		if !edge.oneWay {
+			link.ReverseWeight = finiteOrNil(edge.reverseWeight)
+		}
+		document.Links = append(document.Links, link)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+func (asonn *Asonn) exportGraph(opts []ExportOption) exportGraph {
+	var o exportOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	graph := exportGraph{ids: make(map[*Node]int)}
+	for _, node := range asonn.Nodes {
+		if _, ok := graph.ids[node]; ok {
+			continue
+		}
+		if o.types != nil && !o.types[node.Type] {
+			continue
+		}
+		if o.class != "" && !belongsToClass(node, o.class) {
+			continue
+		}
+		graph.ids[node] = len(graph.nodes)
+		graph.nodes = append(graph.nodes, node)
+	}
+	for from, node := range graph.nodes {
+		seen := make(map[int]bool)
+		for _, connection := range node.Connections {
+			to, ok := graph.ids[connection.Node]
+			if !ok || seen[to] {
+				continue
+			}
+			seen[to] = true
+			reverse, bidirectional := connectionWeight(connection.Node, node)
+			if bidirectional && to < from {
+				continue // Written from the other end
+			}
+			graph.edges = append(graph.edges, exportEdge{from: from, to: to, weight: connection.Weight, reverseWeight: reverse, oneWay: !bidirectional})
+		}
+	}
+	return graph
+}
+
+func belongsToClass(node *Node, class string) bool {
+	switch node.Type {
+	case Feature, Target:
+		return true
+	case Class:
+		return classLabel(node) == class
+	case Combination, Object:
+		return getClassOfObject(node) == class
+	case Range:
+		for _, connection := range node.Connections {
+			if connection.Node.Type == Combination && getClassOfObject(connection.Node) == class {
+				return true
+			}
+		}
+	case Value:
+		for _, connection := range node.Connections {
+			if connection.Node.Type == Object && getClassOfObject(connection.Node) == class || connection.Node.Type == Class && classLabel(connection.Node) == class {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func connectionWeight(from *Node, to *Node) (float64, bool) {
+	for _, connection := range from.Connections {
+		if connection.Node == to {
+			return connection.Weight, true
+		}
+	}
+	return 0, false
+}
+
+func nodeLabel(node *Node) string {
+	switch value := node.Value.(type) {
+	case [2]interface{}:
+		return fmt.Sprintf("[%v, %v]", value[0], value[1])
+	case Categories:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func formatWeight(weight float64) string {
+	return strconv.FormatFloat(weight, 'g', 4, 64)
+}
+
+func finiteOrNil(value float64) *float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	return &value
+}
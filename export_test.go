@@ -0,0 +1,61 @@
+package gasonn
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var overlappingX = append(append([][]string{}, trainX...), []string{"1.15", "2.65"})
+
+var overlappingY = append(append([]string{}, trainY...), "n")
+
+func TestExportDOT(t *testing.T) {
+	asonn, err := Train(overlappingX, overlappingY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err := asonn.ExportDOT(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	dot := buffer.String()
+	if !strings.HasPrefix(dot, "digraph asonn {") || !strings.HasSuffix(dot, "}\n") {
+		t.Errorf("Invalid DOT document")
+	}
+	if !strings.Contains(dot, "style=dashed") {
+		t.Errorf("Inhibitory connections not dashed")
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	asonn, err := Train(trainX, trainY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err := asonn.ExportJSON(&buffer, ExportClass("p"), ExportTypes(Combination, Range)); err != nil {
+		t.Fatal(err)
+	}
+	var document jsonGraph
+	if err := json.Unmarshal(buffer.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	if len(document.Nodes) == 0 {
+		t.Fatalf("No nodes exported")
+	}
+	for _, node := range document.Nodes {
+		if node.Type != Combination && node.Type != Range {
+			t.Errorf("Exported %s node", node.Type)
+		}
+		if node.Type == Combination && node.Class != "p" {
+			t.Errorf("Exported combination of class %s", node.Class)
+		}
+	}
+	for _, link := range document.Links {
+		if link.Source >= len(document.Nodes) || link.Target >= len(document.Nodes) {
+			t.Errorf("Link to node outside of the document")
+		}
+	}
+}
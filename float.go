@@ -0,0 +1,150 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrLabelType     = errors.New("Class label has another type")
+	ErrLabelConflict = errors.New("Class labels print alike")
+	ErrNoWinner      = errors.New("No combination is activated")
+	ErrOption        = errors.New("Option doesn't apply")
+)
+
+// FloatModel is a model trained on float64 features whose Class nodes carry
+// labels of type L. Only models with string, int or float64 labels can be
+// saved; saving others fails with an unsupported node value type.
+type FloatModel[L comparable] struct {
+	Asonn  *Asonn
+	labels map[*Node]L
+}
+
+// TrainFloat trains a model on rows of x, whose values are named by
+// featureNames, and their labels y without formatting either as strings.
+// NaN values are missing and skipped. Classes are told apart by their
+// printed labels during training, so labels that differ but print alike are
+// rejected with ErrLabelConflict. WithSchema, WithMissing and
+// WithTargetIntervals are rejected with ErrOption.
+func TrainFloat[L comparable](x [][]float64, featureNames []string, y []L, opts ...Option) (*FloatModel[L], error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case o.schema != nil:
+		return nil, fmt.Errorf("%w: WithSchema, features are numeric", ErrOption)
+	case o.missing != SkipMissing:
+		return nil, fmt.Errorf("%w: WithMissing, NaN values are skipped", ErrOption)
+	case o.intervals != 0:
+		return nil, fmt.Errorf("%w: WithTargetIntervals", ErrOption)
+	}
+	if len(x) == 0 || len(featureNames) == 0 {
+		return nil, ErrEmptyData
+	}
+	if len(y) != len(x) {
+		return nil, fmt.Errorf("%w: %d rows, %d labels", ErrLengthMismatch, len(x), len(y))
+	}
+	printed := make(map[L]string)
+	owners := make(map[string]L)
+	for _, label := range y {
+		if _, ok := printed[label]; ok {
+			continue
+		}
+		printed[label] = classLabel(&Node{Value: label})
+		if other, ok := owners[printed[label]]; ok {
+			return nil, fmt.Errorf("%w: %#v and %#v", ErrLabelConflict, other, label)
+		}
+		owners[printed[label]] = label
+	}
+	rows := make([][]interface{}, len(x))
+	labels := make([]interface{}, len(y))
+	for i, row := range x {
+		if len(row) != len(featureNames) {
+			return nil, fmt.Errorf("%w: row %d has %d values, %d feature names", ErrRaggedRow, i, len(row), len(featureNames))
+		}
+		rows[i] = make([]interface{}, len(row))
+		for j, value := range row {
+			if !math.IsNaN(value) {
+				rows[i][j] = value
+			}
+		}
+		labels[i] = y[i]
+	}
+	schema := make(Schema, len(featureNames))
+	for j, name := range featureNames {
+		schema[j] = Column{Name: name, Type: NumericColumn}
+	}
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	asonn := &Asonn{Config: &o.config, Schema: schema}
+	classNodes := asonn.addObjectNodes(featureNames, rows, labels)
+	if err := asonn.build(o.strategy, classNodes); err != nil {
+		return nil, err
+	}
+	return FloatModelOf[L](asonn)
+}
+
+// FloatModelOf wraps a model whose Class nodes carry labels of type L, like a
+// loaded model trained by TrainFloat.
+func FloatModelOf[L comparable](asonn *Asonn) (*FloatModel[L], error) {
+	labels := make(map[*Node]L)
+	for _, classNode := range asonn.graph().nodes(Class) {
+		label, ok := classNode.Value.(L)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T is not %T", ErrLabelType, classNode.Value, label)
+		}
+		labels[classNode] = label
+	}
+	return &FloatModel[L]{Asonn: asonn, labels: labels}, nil
+}
+
+// Predict classifies rows of x, whose values follow the feature names the
+// model was trained with. NaN values are missing. A row activating no
+// combination, such as one with all values missing, fails with ErrNoWinner.
+func (model *FloatModel[L]) Predict(x [][]float64) ([]L, error) {
+	if _, err := model.Asonn.classes(); err != nil {
+		return nil, err
+	}
+	features := model.Asonn.Header()
+	predictions := make([]L, 0, len(x))
+	for i, row := range x {
+		if len(row) != len(features) {
+			return nil, fmt.Errorf("%w: row %d has %d values, model has %d features", ErrRaggedRow, i, len(row), len(features))
+		}
+		state, winner := model.Asonn.activateInputs(features, func(j int, feature *Node) (string, float64, bool) {
+			return "", row[j], !math.IsNaN(row[j])
+		})
+		if winner == nil || state[winner] <= 0 {
+			return nil, fmt.Errorf("%w: row %d", ErrNoWinner, i)
+		}
+		label, ok := model.labels[classNodeOf(winner)]
+		if !ok {
+			return nil, fmt.Errorf("%w: combination %v has no class", ErrInvalidModel, winner.Value)
+		}
+		predictions = append(predictions, label)
+	}
+	return predictions, nil
+}
+
+// PredictOne classifies a single row like Predict.
+func (model *FloatModel[L]) PredictOne(row []float64) (L, error) {
+	predictions, err := model.Predict([][]float64{row})
+	if err != nil {
+		var label L
+		return label, err
+	}
+	return predictions[0], nil
+}
+
+// classNodeOf returns the Class node a combination or object belongs to.
+func classNodeOf(node *Node) *Node {
+	for _, connection := range node.Connections {
+		if connection.Node.Type == Class {
+			return connection.Node
+		}
+	}
+	return nil
+}
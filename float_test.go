@@ -0,0 +1,120 @@
+package gasonn
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+type species struct {
+	genus string
+	id    int
+}
+
+var (
+	floatX = [][]float64{{1.0, 2.5}, {1.2, 2.7}, {3.1, 0.5}, {3.3, 0.7}, {1.1, 2.6}, {3.0, 0.4}}
+	floatY = []int{1, 1, 0, 0, 1, 0}
+)
+
+func TestTrainFloat(t *testing.T) {
+	model, err := TrainFloat(floatX, []string{"a", "b"}, floatY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	predictions, err := model.Predict(floatX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, label := range predictions {
+		if label != floatY[i] {
+			t.Errorf("Row %d classified as %d instead of %d", i, label, floatY[i])
+		}
+	}
+	label, err := model.PredictOne([]float64{math.NaN(), 0.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label != 0 {
+		t.Errorf("Row with a missing value classified as %d instead of 0", label)
+	}
+	var buffer bytes.Buffer
+	if err := model.Asonn.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := FloatModelOf[int](loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label, err := reloaded.PredictOne([]float64{1.1, 2.5}); err != nil || label != 1 {
+		t.Errorf("Reloaded model classified row as %d, %v instead of 1", label, err)
+	}
+	if _, err := FloatModelOf[string](loaded); !errors.Is(err, ErrLabelType) {
+		t.Errorf("Got error %v instead of %v", err, ErrLabelType)
+	}
+}
+
+func TestTrainFloatStructLabels(t *testing.T) {
+	positive, negative := species{"p", 1}, species{"n", 2}
+	y := []species{positive, positive, negative, negative, positive, negative}
+	model, err := TrainFloat(floatX, []string{"a", "b"}, y, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	predictions, err := model.Predict(floatX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, label := range predictions {
+		if label != y[i] {
+			t.Errorf("Row %d classified as %v instead of %v", i, label, y[i])
+		}
+	}
+	if err := model.Asonn.Save(&bytes.Buffer{}); err == nil {
+		t.Error("Model with struct labels saved")
+	}
+}
+
+type trainFloatErrorTestData struct {
+	name string
+	x    [][]float64
+	y    []int
+	err  error
+}
+
+var trainFloatErrorTests = []trainFloatErrorTestData{
+	{"empty", nil, nil, ErrEmptyData},
+	{"length mismatch", floatX, floatY[1:], ErrLengthMismatch},
+	{"ragged row", [][]float64{{1.0}}, []int{1}, ErrRaggedRow},
+}
+
+func TestTrainFloatErrors(t *testing.T) {
+	for _, testData := range trainFloatErrorTests {
+		if _, err := TrainFloat(testData.x, []string{"a", "b"}, testData.y); !errors.Is(err, testData.err) {
+			t.Errorf("%s: got error %v instead of %v", testData.name, err, testData.err)
+		}
+	}
+	model, err := TrainFloat(floatX, []string{"a", "b"}, floatY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := model.PredictOne([]float64{1.0}); !errors.Is(err, ErrRaggedRow) {
+		t.Errorf("Got error %v instead of %v", err, ErrRaggedRow)
+	}
+	if _, err := model.PredictOne([]float64{math.NaN(), math.NaN()}); !errors.Is(err, ErrNoWinner) {
+		t.Errorf("Got error %v instead of %v", err, ErrNoWinner)
+	}
+	for _, option := range []Option{WithSchema(Schema{{Name: "a"}}), WithMissing(ImputeMean), WithTargetIntervals(2)} {
+		if _, err := TrainFloat(floatX, []string{"a", "b"}, floatY, option); !errors.Is(err, ErrOption) {
+			t.Errorf("Got error %v instead of %v", err, ErrOption)
+		}
+	}
+	alike := []interface{}{1, 1, "1", "1", 1, "1"}
+	if _, err := TrainFloat(floatX, []string{"a", "b"}, alike); !errors.Is(err, ErrLabelConflict) {
+		t.Errorf("Got error %v instead of %v", err, ErrLabelConflict)
+	}
+}
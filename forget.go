@@ -0,0 +1,204 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownObject = errors.New("Unknown object")
+
+// Forget removes a training object from the model. objectID names the Object
+// node, "O"+i for row i of the training data. Values no other object has are
+// removed and ADEF weights of objects sharing the remaining ones are
+// recomputed. In single-layer models combinations left without an object of
+// their class are removed, range ends that only the object reached are pulled
+// in to the remaining objects and range weights are recomputed. Multi-layer
+// combinations the object changes are rebuilt.
+func (asonn *Asonn) Forget(objectID string) error {
+	if asonn.IsRegression() {
+		return ErrRegression
+	}
+	if len(asonn.graph().nodes(Combination)) == 0 {
+		return ErrNoSamples
+	}
+	multiLayer := asonn.graph().keepsValues()
+	if !multiLayer {
+		if len(asonn.samples) == 0 {
+			return ErrNoSamples
+		}
+		asonn.Nodes = append(asonn.Nodes, asonn.samples...)
+		asonn.Reindex()
+	}
+	var object *Node
+	for _, node := range asonn.graph().nodes(Object) {
+		if node.Value == objectID {
+			object = node
+			break
+		}
+	}
+	if object == nil {
+		if !multiLayer {
+			asonn.removeValueAndObjectNodes()
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownObject, objectID)
+	}
+	change := layerChange{class: getClassOfObject(object), point: asonn.graph().pointOf(object)}
+	asonn.removeSample(object)
+	if multiLayer {
+		return asonn.refreshLayers([]layerChange{change})
+	}
+	asonn.repairCombinations(change.point)
+	asonn.updateRangeToCombinationConnectionWeights()
+	asonn.removeValueAndObjectNodes()
+	return nil
+}
+
+// removeSample disconnects object together with values and a class left
+// without objects, relinks values of the changed features and reweighs
+// objects sharing values with it.
+func (asonn *Asonn) removeSample(object *Node) {
+	graph := asonn.graph()
+	removed := map[*Node]bool{object: true}
+	var values, changedFeatures []*Node
+	var classNode *Node
+	for _, connection := range object.Connections {
+		switch connection.Node.Type {
+		case Value:
+			values = append(values, connection.Node)
+		case Class:
+			classNode = connection.Node
+		}
+	}
+	isolate(object)
+	var kept []*Node
+	for _, valueNode := range values {
+		if countObjectConnections(valueNode) > 0 {
+			kept = append(kept, valueNode)
+			continue
+		}
+		changedFeatures = append(changedFeatures, graph.valueFeatures[valueNode])
+		removed[valueNode] = true
+		isolate(valueNode)
+	}
+	if classNode != nil && countObjectConnections(classNode) == 0 {
+		removed[classNode] = true
+		isolate(classNode)
+	}
+	asonn.Nodes = withoutNodes(asonn.Nodes, removed)
+	asonn.Reindex()
+	graph = asonn.graph()
+	for _, feature := range changedFeatures {
+		unlinkValues(graph.featureValues[feature])
+		if len(graph.featureValues[feature]) > 0 {
+			linkValues(graph.featureValues[feature])
+		}
+	}
+	weighed := make(map[*Node]bool)
+	for _, valueNode := range kept {
+		for _, connection := range valueNode.Connections {
+			if connection.Node.Type == Object && !weighed[connection.Node] {
+				weighed[connection.Node] = true
+				weighObject(connection.Node)
+			}
+		}
+	}
+}
+
+// repairCombinations removes combinations that represent no object of their
+// class and shrinks their other ranges to the remaining objects where point,
+// the values of the forgotten object, reached further than they do.
+func (asonn *Asonn) repairCombinations(point map[*Node]interface{}) {
+	graph := asonn.graph()
+	var removed []*Node
+	for _, combination := range graph.nodes(Combination) {
+		class := getClassOfObject(combination)
+		var seeds []*Node
+		for _, connection := range combination.Connections {
+			if connection.Node.Type == Object && getClassOfObject(connection.Node) == class && !containsNode(seeds, connection.Node) {
+				seeds = append(seeds, connection.Node)
+			}
+		}
+		if len(seeds) == 0 {
+			removed = append(removed, combination)
+			continue
+		}
+		for _, connection := range combination.Connections {
+			if connection.Node.Type == Range {
+				graph.shrinkRange(connection.Node, point, seeds)
+			}
+		}
+	}
+	asonn.removeCombinations(removed...)
+}
+
+// shrinkRange pulls an end of rangeNode equal to the value of point, or drops
+// its category, when no seed has that value.
+func (graph *graphIndex) shrinkRange(rangeNode *Node, point map[*Node]interface{}, seeds []*Node) {
+	feature := graph.rangeFeatures[rangeNode]
+	value, known := point[feature]
+	if !known {
+		return
+	}
+	var values []*Node
+	for _, seed := range seeds {
+		for _, connection := range seed.Connections {
+			if graph.valueFeatures[connection.Node] == feature {
+				values = append(values, connection.Node)
+			}
+		}
+	}
+	if findValue(values, value) != nil || len(values) == 0 {
+		return
+	}
+	if held, ok := rangeNode.Value.(Categories); ok {
+		var kept Categories
+		for _, category := range held {
+			if category != value {
+				kept = append(kept, category)
+			}
+		}
+		rangeNode.Value = kept
+		graph.syncRangeValues(rangeNode)
+		return
+	}
+	number, err := convertToFloat64(value)
+	min, max, ok := rangeLimits(rangeNode)
+	if err != nil || !ok || number != min && number != max {
+		return
+	}
+	lowest, highest := values[0], values[0]
+	for _, valueNode := range values {
+		if graph.valueFloats[valueNode] < graph.valueFloats[lowest] {
+			lowest = valueNode
+		}
+		if graph.valueFloats[valueNode] > graph.valueFloats[highest] {
+			highest = valueNode
+		}
+	}
+	bounds := rangeNode.Value.([2]interface{})
+	if number == min && graph.valueFloats[lowest] > min {
+		bounds[0] = lowest.Value
+	}
+	if number == max && graph.valueFloats[highest] < max {
+		bounds[1] = highest.Value
+	}
+	rangeNode.Value = bounds
+	graph.syncRangeValues(rangeNode)
+}
+
+// isolate removes all connections of node.
+func isolate(node *Node) {
+	for _, connection := range append(ConnectionSlice(nil), node.Connections...) {
+		removeConnection(node, connection.Node)
+	}
+}
+
+func withoutNodes(nodes []*Node, removed map[*Node]bool) []*Node {
+	var kept []*Node
+	for _, node := range nodes {
+		if !removed[node] {
+			kept = append(kept, node)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,148 @@
+package gasonn
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func objectWeights(asonn *Asonn) map[interface{}][]float64 {
+	weights := make(map[interface{}][]float64)
+	for _, node := range append(append([]*Node(nil), asonn.Nodes...), asonn.samples...) {
+		if node.Type != Object {
+			continue
+		}
+		var objectWeights []float64
+		for _, connection := range node.Connections {
+			if connection.Node.Type == Value {
+				objectWeights = append(objectWeights, connection.Weight)
+			}
+		}
+		weights[node.Value] = objectWeights
+	}
+	return weights
+}
+
+// forgottenX and forgottenY add O7 to the training data, which the ranges of
+// a model trained on trainX alone don't reach.
+var (
+	forgottenX = append(append([][]string{}, trainX...), []string{"9.0", "1.2"})
+	forgottenY = append(append([]string{}, trainY...), "p")
+)
+
+func TestForget(t *testing.T) {
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		asonn, err := Train(forgottenX, forgottenY, WithStrategy(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := asonn.Forget("O7"); err != nil {
+			t.Fatal(err)
+		}
+		assertForgotten(t, strategy, asonn)
+	}
+}
+
+// assertForgotten checks that asonn, which forgot O7 of forgottenX, matches
+// a model trained on trainX.
+func assertForgotten(t *testing.T, strategy Strategy, asonn *Asonn) {
+	t.Helper()
+	expected, err := Train(trainX, trainY, WithStrategy(strategy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := objectWeights(expected)
+	for id, weights := range objectWeights(asonn) {
+		if len(weights) != len(want[id]) {
+			t.Fatalf("Strategy %d: object %v has %d values instead of %d", strategy, id, len(weights), len(want[id]))
+		}
+		for i := range weights {
+			if diff := weights[i] - want[id][i]; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Strategy %d: object %v has weight %f instead of %f", strategy, id, weights[i], want[id][i])
+			}
+		}
+	}
+	if len(objectWeights(asonn)) != len(want) {
+		t.Errorf("Strategy %d: model keeps %d objects instead of %d", strategy, len(objectWeights(asonn)), len(want))
+	}
+	for _, node := range asonn.Nodes {
+		if node.Type != Range {
+			continue
+		}
+		if min, max, ok := rangeLimits(node); ok && (min > 3.3 || max > 3.3) {
+			t.Errorf("Strategy %d: range %v still reaches the forgotten object", strategy, node.Value)
+		}
+	}
+	if got := accuracy(t, asonn, trainX, trainY); got != 1 {
+		t.Errorf("Strategy %d: accuracy %f after forgetting", strategy, got)
+	}
+}
+
+func TestForgetLastOfClass(t *testing.T) {
+	x := append(append([][]string{}, trainX...), []string{"5.0", "5.0"})
+	y := append(append([]string{}, trainY...), "q")
+	asonn, err := Train(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := asonn.Forget("O7"); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range asonn.Nodes {
+		if node.Type == Class && node.Value == "q" {
+			t.Errorf("Class q is kept after forgetting its only object")
+		}
+		if node.Type == Combination && getClassOfObject(node) == "" {
+			t.Errorf("Combination %v has no class", node.Value)
+		}
+	}
+	if err := asonn.Learn([]string{"1.05", "2.55"}, "p"); err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[interface{}]bool)
+	for _, node := range asonn.Nodes {
+		if node.Type == Combination {
+			if names[node.Value] {
+				t.Errorf("Combination name %v is used twice", node.Value)
+			}
+			names[node.Value] = true
+		}
+	}
+}
+
+func TestForgetAfterLoad(t *testing.T) {
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		asonn, err := Train(forgottenX, forgottenY, WithStrategy(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buffer bytes.Buffer
+		if err := asonn.Save(&buffer); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(&buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := loaded.Forget("O7"); err != nil {
+			t.Fatalf("Strategy %d: %v", strategy, err)
+		}
+		assertForgotten(t, strategy, loaded)
+	}
+}
+
+func TestForgetErrors(t *testing.T) {
+	asonn, err := Train(trainX, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := asonn.Forget("O42"); !errors.Is(err, ErrUnknownObject) {
+		t.Errorf("Got error %v instead of %v", err, ErrUnknownObject)
+	}
+	if got := accuracy(t, asonn, trainX, trainY); got != 1 {
+		t.Errorf("Accuracy %f after forgetting an unknown object", got)
+	}
+	if err := (&Asonn{}).Forget("O1"); !errors.Is(err, ErrNoSamples) {
+		t.Errorf("Got error %v instead of %v", err, ErrNoSamples)
+	}
+}
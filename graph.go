@@ -0,0 +1,200 @@
+package gasonn
+
+// graphIndex caches typed views of asonn.Nodes so training and inference do
+// not scan the whole graph. Training keeps it up to date through addNodes and
+// Reindex rebuilds it whenever asonn.Nodes is replaced.
+type graphIndex struct {
+	size   int
+	config Config
+	// memberships holds the membership function of every feature.
+	memberships map[*Node]MembershipFunc
+	byType      map[string][]*Node
+	// features maps feature names to Feature nodes, several when the header
+	// repeats a name.
+	features map[interface{}][]*Node
+	// featureValues holds Value nodes of every feature in connection order.
+	featureValues map[*Node][]*Node
+	valueFloats   map[*Node]float64
+	valueFeatures map[*Node]*Node
+	featureSpans  map[*Node]float64
+	schema        Schema
+	columns       map[*Node]Column
+	// categorical marks features with string values or category ranges.
+	categorical map[*Node]bool
+	// categoryRanges maps categories of categorical features to the reduced
+	// Range nodes holding them.
+	categoryRanges map[*Node]map[string][]*Node
+	// featureRanges holds Range nodes of every feature in connection order.
+	featureRanges map[*Node][]*Node
+	rangeFeatures map[*Node]*Node
+	// intervals indexes reduced ranges of every feature. A feature whose
+	// ranges changed since the index was built has no entry.
+	intervals map[*Node]*intervalIndex
+	// rangeOwners maps Range nodes to their connection from a combination.
+	rangeOwners map[*Node]rangeOwner
+	// classObjects counts Object entries of asonn.Nodes per class.
+	classObjects map[string]int
+}
+
+type rangeOwner struct {
+	combination *Node
+	connection  int
+}
+
+func newGraphIndex(nodes []*Node, config Config, schema Schema) *graphIndex {
+	index := &graphIndex{
+		config:         config,
+		schema:         schema,
+		columns:        make(map[*Node]Column),
+		memberships:    make(map[*Node]MembershipFunc),
+		byType:         make(map[string][]*Node),
+		features:       make(map[interface{}][]*Node),
+		featureValues:  make(map[*Node][]*Node),
+		valueFloats:    make(map[*Node]float64),
+		valueFeatures:  make(map[*Node]*Node),
+		featureSpans:   make(map[*Node]float64),
+		categorical:    make(map[*Node]bool),
+		categoryRanges: make(map[*Node]map[string][]*Node),
+		featureRanges:  make(map[*Node][]*Node),
+		rangeFeatures:  make(map[*Node]*Node),
+		intervals:      make(map[*Node]*intervalIndex),
+		rangeOwners:    make(map[*Node]rangeOwner),
+		classObjects:   make(map[string]int),
+	}
+	index.add(nodes...)
+	for _, feature := range index.nodes(Feature) {
+		index.intervals[feature] = newIntervalIndex(index.featureRanges[feature], index.memberships[feature], config.ActivationScale)
+	}
+	return index
+}
+
+func (index *graphIndex) add(nodes ...*Node) {
+	index.size += len(nodes)
+	for _, node := range nodes {
+		index.byType[node.Type] = append(index.byType[node.Type], node)
+		switch node.Type {
+		case Feature:
+			index.features[node.Value] = append(index.features[node.Value], node)
+			index.addFeature(node)
+		case Object:
+			index.classObjects[getClassOfObject(node)]++
+		case Range:
+			if feature, err := getFeatureConnection(node); err == nil {
+				index.featureRanges[feature] = append(index.featureRanges[feature], node)
+				index.rangeFeatures[node] = feature
+				delete(index.intervals, feature)
+				index.addCategories(feature, node)
+			}
+		case Combination:
+			index.addCombination(node)
+		}
+	}
+}
+
+func (index *graphIndex) addFeature(feature *Node) {
+	if _, ok := index.featureValues[feature]; ok {
+		return
+	}
+	index.memberships[feature] = index.config.membership(feature)
+	name, _ := feature.Value.(string)
+	// A header naming several features alike has a column for each, in order.
+	if column, ok := index.schema.nthColumn(name, len(index.features[feature.Value])-1); ok {
+		index.columns[feature] = column
+		if column.Type == CategoricalColumn {
+			index.categorical[feature] = true
+		}
+	}
+	var values []*Node
+	// The span has always counted Range nodes of the feature as 0, so 0 stays
+	// part of it and trained models do not change.
+	minVal, maxVal := 0.0, 0.0
+	for _, connection := range feature.Connections {
+		if connection.Node.Type != Value {
+			continue
+		}
+		if _, ok := connection.Node.Value.(string); ok {
+			index.categorical[feature] = true
+		}
+		val, _ := convertToFloat64(connection.Node.Value)
+		values = append(values, connection.Node)
+		index.valueFloats[connection.Node] = val
+		index.valueFeatures[connection.Node] = feature
+		if val < minVal {
+			minVal = val
+		} else if val > maxVal {
+			maxVal = val
+		}
+	}
+	index.featureValues[feature] = values
+	index.featureSpans[feature] = maxVal - minVal
+}
+
+// input returns the activation input of a cell of feature, read as the
+// schema column of the feature when the model has one.
+func (index *graphIndex) input(feature *Node, cell string) float64 {
+	if column, ok := index.columns[feature]; ok {
+		if value, err := column.parse(cell); err == nil {
+			return activationInput(value)
+		}
+	}
+	return activationInput(convertToCorrectType(cell))
+}
+
+func (index *graphIndex) addCategories(feature *Node, rangeNode *Node) {
+	categories, ok := rangeNode.Value.(Categories)
+	if !ok {
+		return
+	}
+	index.categorical[feature] = true
+	if index.categoryRanges[feature] == nil {
+		index.categoryRanges[feature] = make(map[string][]*Node)
+	}
+	for _, category := range categories {
+		index.categoryRanges[feature][category] = append(index.categoryRanges[feature][category], rangeNode)
+	}
+}
+
+func (index *graphIndex) addCombination(combination *Node) {
+	for i, connection := range combination.Connections {
+		if connection.Node.Type != Range {
+			continue
+		}
+		if _, ok := index.rangeOwners[connection.Node]; !ok {
+			index.rangeOwners[connection.Node] = rangeOwner{combination: combination, connection: i}
+		}
+	}
+}
+
+func (index *graphIndex) nodes(nodeType string) []*Node {
+	return index.byType[nodeType]
+}
+
+func (index *graphIndex) featuresNumber() float64 {
+	return float64(len(index.byType[Feature]))
+}
+
+// graph returns the index of asonn.Nodes. A model without one, like one
+// built as a literal, gets a temporary index that isn't stored, so inference
+// never writes to a shared model.
+func (asonn *Asonn) graph() *graphIndex {
+	if asonn.index != nil {
+		return asonn.index
+	}
+	return newGraphIndex(asonn.Nodes, asonn.config(), asonn.Schema)
+}
+
+// Reindex rebuilds the lookups of the graph that training and inference use.
+// Methods of Asonn keep them up to date, so it only needs to be called after
+// changing Nodes, their values or connections directly.
+func (asonn *Asonn) Reindex() {
+	asonn.index = newGraphIndex(asonn.Nodes, asonn.config(), asonn.Schema)
+}
+
+func (asonn *Asonn) addNodes(nodes ...*Node) {
+	asonn.Nodes = append(asonn.Nodes, nodes...)
+	if asonn.index != nil {
+		asonn.index.add(nodes...)
+	} else {
+		asonn.Reindex()
+	}
+}
@@ -0,0 +1,156 @@
+package gasonn
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestGraphIndex(t *testing.T) {
+	asonn, err := Train(overlappingX, overlappingY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh := newGraphIndex(asonn.Nodes, asonn.config(), asonn.Schema)
+	if asonn.index.size != fresh.size {
+		t.Fatalf("Index covers %d nodes instead of %d", asonn.index.size, fresh.size)
+	}
+	for _, nodeType := range []string{Feature, Value, Object, Class, Range, Combination} {
+		if len(asonn.index.nodes(nodeType)) != len(fresh.nodes(nodeType)) {
+			t.Errorf("Index has %d %s nodes instead of %d", len(asonn.index.nodes(nodeType)), nodeType, len(fresh.nodes(nodeType)))
+		}
+	}
+	if asonn.getFeaturesNumber() != 2 {
+		t.Errorf("Counted %v features instead of 2", asonn.getFeaturesNumber())
+	}
+	for _, feature := range asonn.index.nodes(Feature) {
+		values := asonn.index.featureValues[feature]
+		for i := 1; i < len(values); i++ {
+			if asonn.index.valueFloats[values[i-1]] < asonn.index.valueFloats[values[i]] {
+				t.Errorf("Values of feature %v not sorted", feature.Value)
+			}
+		}
+		if len(asonn.index.featureRanges[feature]) != len(fresh.featureRanges[feature]) {
+			t.Errorf("Feature %v has %d ranges instead of %d", feature.Value, len(asonn.index.featureRanges[feature]), len(fresh.featureRanges[feature]))
+		}
+	}
+}
+
+func TestReindex(t *testing.T) {
+	asonn, err := Train(overlappingX, overlappingY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	combinations := len(asonn.graph().nodes(Combination))
+	var kept []*Node
+	for _, node := range asonn.Nodes {
+		if node.Type != Combination || len(kept) == 0 || kept[len(kept)-1].Type != Combination {
+			kept = append(kept, node)
+		}
+	}
+	asonn.Nodes = kept
+	if got := len(asonn.graph().nodes(Combination)); got != combinations {
+		t.Errorf("Index changed to %d combinations without Reindex", got)
+	}
+	asonn.Reindex()
+	if got, want := len(asonn.graph().nodes(Combination)), len(newGraphIndex(kept, asonn.config(), asonn.Schema).nodes(Combination)); got != want || got >= combinations {
+		t.Errorf("Reindexed %d combinations instead of %d", got, want)
+	}
+}
+
+// The shape of PMLB's magic dataset.
+const (
+	magicRows     = 19020
+	magicFeatures = 10
+)
+
+// syntheticData generates rows of noisy numeric features whose class is the
+// sign of their sum, resembling large PMLB datasets such as magic.
+func syntheticData(rows int, features int) ([][]string, []string) {
+	random := rand.New(rand.NewSource(1))
+	x := [][]string{{}}
+	y := []string{"target"}
+	for j := 0; j < features; j++ {
+		x[0] = append(x[0], "f"+strconv.Itoa(j))
+	}
+	for i := 0; i < rows; i++ {
+		row := make([]string, features)
+		sum := 0.0
+		for j := range row {
+			value := float64(random.Intn(200)-100) / 10
+			sum += value
+			row[j] = strconv.FormatFloat(value, 'f', 1, 64)
+		}
+		x = append(x, row)
+		y = append(y, strconv.FormatBool(sum > 0))
+	}
+	return x, y
+}
+
+func benchmarkTrain(b *testing.B, x [][]string, y []string, strategy Strategy) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Train(x, y, WithStrategy(strategy)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkClassify(b *testing.B, x [][]string, y []string, strategy Strategy) {
+	asonn, err := Train(x, y, WithStrategy(strategy))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := asonn.Classify(x); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTrainIris(b *testing.B) {
+	x, y := loadFixture(b, "iris")
+	benchmarkTrain(b, x, y, SingleLayer)
+}
+
+func BenchmarkTrainMonk1(b *testing.B) {
+	x, y := loadFixture(b, "monk1")
+	benchmarkTrain(b, x, y, SingleLayer)
+}
+
+// BenchmarkTrainLarge trains on 200 noisy rows. Training on all of a
+// magic-sized dataset doesn't finish in reasonable time, before or after the
+// graph index, as almost every noisy row becomes a combination.
+func BenchmarkTrainLarge(b *testing.B) {
+	x, y := syntheticData(200, 6)
+	benchmarkTrain(b, x, y, SingleLayer)
+}
+
+func BenchmarkClassifyIris(b *testing.B) {
+	x, y := loadFixture(b, "iris")
+	benchmarkClassify(b, x, y, SingleLayer)
+}
+
+func BenchmarkClassifyMonk1(b *testing.B) {
+	x, y := loadFixture(b, "monk1")
+	benchmarkClassify(b, x, y, SingleLayer)
+}
+
+// BenchmarkClassifyMagic classifies as many rows of as many features as
+// PMLB's magic with a model trained on the first 50.
+func BenchmarkClassifyMagic(b *testing.B) {
+	x, y := syntheticData(magicRows, magicFeatures)
+	asonn, err := Train(x[:51], y[:51], WithStrategy(SingleLayer))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := asonn.Classify(x); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package gasonn
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// negligibleActivation is the Range activation below which a range is not
+// evaluated during prediction.
+const negligibleActivation = 1e-9
+
+// activationTail is the half width, relative to the scaled half width of a
+// range, of the span outside of which the range activation is below
+// negligibleActivation.
+var activationTail = math.Sqrt(1 - 2*math.Log(negligibleActivation))
+
+type interval struct {
+	low, high float64
+	node      *Node
+}
+
+// intervalIndex finds ranges whose non-negligible span contains a value. The
+// intervals are sorted by low end and form an implicit balanced search tree,
+// with maxHigh holding the highest end of every subtree.
+type intervalIndex struct {
+	intervals []interval
+	maxHigh   []float64
+}
+
+// newIntervalIndex returns nil when a range is not reduced yet, as training
+// still changes it. membership and scale are those of the feature.
+func newIntervalIndex(ranges []*Node, membership MembershipFunc, scale float64) *intervalIndex {
+	index := &intervalIndex{}
+	for _, rangeNode := range ranges {
+		min, max, ok := rangeBounds(rangeNode)
+		if !ok {
+			return nil
+		}
+		low, high := membership.Support(min, max, scale*(max-min))
+		index.intervals = append(index.intervals, interval{low: low, high: high, node: rangeNode})
+	}
+	sort.SliceStable(index.intervals, func(i, j int) bool {
+		return index.intervals[i].low < index.intervals[j].low
+	})
+	index.maxHigh = make([]float64, len(index.intervals))
+	index.fillMaxHigh(0, len(index.intervals))
+	return index
+}
+
+func (index *intervalIndex) fillMaxHigh(from, to int) float64 {
+	if from >= to {
+		return math.Inf(-1)
+	}
+	mid := (from + to) / 2
+	high := math.Max(index.intervals[mid].high, math.Max(index.fillMaxHigh(from, mid), index.fillMaxHigh(mid+1, to)))
+	index.maxHigh[mid] = high
+	return high
+}
+
+// stab appends ranges whose span contains value to found.
+func (index *intervalIndex) stab(value float64, found []*Node) []*Node {
+	return index.stabBetween(value, 0, len(index.intervals), found)
+}
+
+func (index *intervalIndex) stabBetween(value float64, from, to int, found []*Node) []*Node {
+	if from >= to {
+		return found
+	}
+	mid := (from + to) / 2
+	if index.maxHigh[mid] < value {
+		return found
+	}
+	found = index.stabBetween(value, from, mid, found)
+	if index.intervals[mid].low > value {
+		return found
+	}
+	if index.intervals[mid].high >= value {
+		found = append(found, index.intervals[mid].node)
+	}
+	return index.stabBetween(value, mid+1, to, found)
+}
+
+// rangesAround returns Range nodes of feature whose activation by value is not
+// negligible, or all of them when the feature is not indexed.
+func (graph *graphIndex) rangesAround(feature *Node, value float64) []*Node {
+	index, ok := graph.intervals[feature]
+	if !ok || index == nil || math.IsNaN(value) {
+		return graph.featureRanges[feature]
+	}
+	return index.stab(value, nil)
+}
+
+func rangeBounds(node *Node) (float64, float64, bool) {
+	bounds, ok := node.Value.([2]interface{})
+	if !ok {
+		return 0, 0, false
+	}
+	min, _ := bounds[0].(float64)
+	max, _ := bounds[1].(float64)
+	return min, max, true
+}
+
+// activationInput converts an input value to the number ranges are activated with.
+func activationInput(value interface{}) float64 {
+	val, err := convertToFloat64(value)
+	if err != nil {
+		strValue, _ := value.(string)
+		val, err = strconv.ParseFloat(strValue, 64)
+		if err != nil {
+			valInt, _ := strconv.Atoi(strValue)
+			val = float64(valInt)
+		}
+	}
+	return val
+}
@@ -0,0 +1,59 @@
+package gasonn
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIntervalIndex(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	var ranges []*Node
+	for i := 0; i < 200; i++ {
+		min := random.Float64() * 100
+		max := min
+		if i%10 != 0 {
+			max += random.Float64() * 10
+		}
+		rangeNode := NewNode([2]interface{}{min, max}, Range)
+		ranges = append(ranges, &rangeNode)
+	}
+	index := newIntervalIndex(ranges, GaussianTail{}, 1)
+	for i := 0; i < 1000; i++ {
+		value := random.Float64()*140 - 20
+		found := make(map[*Node]bool)
+		for _, node := range index.stab(value, nil) {
+			found[node] = true
+		}
+		for _, node := range ranges {
+			if activation := node.activationAt(value, GaussianTail{}, 1); activation >= negligibleActivation && !found[node] {
+				t.Fatalf("Range %v with activation %g not found for %f", node.Value, activation, value)
+			}
+		}
+	}
+}
+
+func TestIntervalIndexUnreduced(t *testing.T) {
+	rangeNode := NewNode([]interface{}{1.0, 2.0}, Range)
+	if newIntervalIndex([]*Node{&rangeNode}, GaussianTail{}, 1) != nil {
+		t.Errorf("Indexed a range that is not reduced")
+	}
+}
+
+func TestPredictNegligibleRanges(t *testing.T) {
+	asonn, err := Train(overlappingX, overlappingY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := [][]string{overlappingX[0], {"100", "-100"}, {"1.15", "2.65"}}
+	exhaustive := &Asonn{Nodes: asonn.Nodes}
+	exhaustive.Reindex()
+	for feature := range exhaustive.index.intervals {
+		delete(exhaustive.index.intervals, feature)
+	}
+	expected := exhaustive.Predict(test)
+	for i, result := range asonn.Predict(test) {
+		if diff := result - expected[i]; diff > 1e-6 || diff < -1e-6 {
+			t.Errorf("Row %d activated with %f instead of %f", i+1, result, expected[i])
+		}
+	}
+}
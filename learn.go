@@ -0,0 +1,970 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMissingLabel = errors.New("Missing class label")
+	ErrNoSamples    = errors.New("Model keeps no training samples")
+)
+
+// Learn adds a labelled row to a trained model. Values of row follow the order
+// of the training features. In single-layer models the row is cut out of the
+// combinations of other classes holding it, joins the combinations of its
+// class holding it and otherwise widens the one that needs the smallest
+// expansion to hold it without reaching into combinations or known objects of
+// other classes. A new combination is seeded only when none can be widened.
+// Multi-layer combinations span the objects of their class within their
+// parent, so only those the new object changes are rebuilt.
+func (asonn *Asonn) Learn(row []string, label string) error {
+	if asonn.IsRegression() {
+		return ErrRegression
+	}
+	graph := asonn.graph()
+	features := graph.nodes(Feature)
+	if len(row) != len(features) {
+		return fmt.Errorf("%w: row has %d values, model has %d features", ErrRaggedRow, len(row), len(features))
+	}
+	if label == "" {
+		return ErrMissingLabel
+	}
+	if len(graph.nodes(Combination)) == 0 {
+		return ErrNoSamples
+	}
+	header := make([]string, len(features))
+	for j, feature := range features {
+		header[j] = fmt.Sprint(feature.Value)
+	}
+	row = asonn.fillMissing([][]string{header, row})[1]
+	point, err := asonn.readPoint(row)
+	if err != nil {
+		return err
+	}
+	if graph.keepsValues() {
+		object := asonn.addSample(point, label)
+		return asonn.refreshLayers([]layerChange{{class: getClassOfObject(object), point: point}})
+	}
+	if len(asonn.samples) == 0 {
+		return ErrNoSamples
+	}
+	asonn.Nodes = append(asonn.Nodes, asonn.samples...)
+	asonn.Reindex()
+	object := asonn.addSample(point, label)
+	asonn.representPoint(object, point, asonn.objectValues())
+	asonn.updateRangeToCombinationConnectionWeights()
+	asonn.removeValueAndObjectNodes()
+	return nil
+}
+
+// readPoint parses row into the value of every feature it has one for.
+func (asonn *Asonn) readPoint(row []string) (map[*Node]interface{}, error) {
+	graph := asonn.graph()
+	point := make(map[*Node]interface{})
+	for j, feature := range graph.nodes(Feature) {
+		column, ok := graph.columns[feature]
+		if ok && column.skips(row[j]) {
+			continue
+		}
+		value, err := sampleValue(graph.featureValues[feature], row[j])
+		if ok {
+			value, err = column.parse(row[j])
+		}
+		if err != nil {
+			return nil, err
+		}
+		point[feature] = value
+	}
+	return point, nil
+}
+
+// addObject adds an Object node connected to the class node of label,
+// creating the class node when needed.
+func (asonn *Asonn) addObject(label string) *Node {
+	objectNode := NewNode("O"+strconv.Itoa(asonn.nextObjectID()), Object)
+	classNode, reused := tryToReuseClassNode(label, asonn.graph().nodes(Class))
+	addConnection(classNode, &objectNode, 1)
+	if !reused {
+		asonn.addNodes(classNode)
+	}
+	asonn.addNodes(&objectNode)
+	return &objectNode
+}
+
+// addSample adds an Object node like addObject and connects it to the values
+// of point, creating Value nodes when needed, and updates ASIM and ADEF
+// weights.
+func (asonn *Asonn) addSample(point map[*Node]interface{}, label string) *Node {
+	objectNode := asonn.addObject(label)
+	graph := asonn.graph()
+	var newValues []*Node
+	for _, feature := range graph.nodes(Feature) {
+		value, ok := point[feature]
+		if !ok {
+			continue
+		}
+		valueNode := findValue(graph.featureValues[feature], value)
+		if valueNode == nil {
+			node := NewNode(value, Value)
+			valueNode = &node
+			addConnection(valueNode, objectNode, 1)
+			insertValue(feature, valueNode)
+			newValues = append(newValues, valueNode)
+		} else {
+			addConnection(valueNode, objectNode, 1)
+		}
+	}
+	asonn.Nodes = append(asonn.Nodes, newValues...)
+	asonn.Reindex()
+	graph = asonn.graph()
+	relinked := make(map[*Node]bool)
+	for _, valueNode := range newValues {
+		feature := graph.valueFeatures[valueNode]
+		if !relinked[feature] {
+			relinked[feature] = true
+			unlinkValues(graph.featureValues[feature])
+			linkValues(graph.featureValues[feature])
+		}
+		for _, rangeNode := range graph.featureRanges[feature] {
+			if holdsValue(rangeNode.Value, valueNode.Value) {
+				addConnection(rangeNode, valueNode, 1)
+			}
+		}
+	}
+	weighed := make(map[*Node]bool)
+	for _, connection := range objectNode.Connections {
+		if connection.Node.Type != Value {
+			continue
+		}
+		for _, objectConnection := range connection.Node.Connections {
+			if objectConnection.Node.Type == Object && !weighed[objectConnection.Node] {
+				weighed[objectConnection.Node] = true
+				weighObject(objectConnection.Node)
+			}
+		}
+	}
+	return objectNode
+}
+
+// box holds the range values of a combination by feature: the ends of
+// numeric ranges and the Categories of categorical ones.
+type box map[*Node]interface{}
+
+// pointBox returns the box spanning point alone.
+func pointBox(point map[*Node]interface{}) box {
+	spanned := make(box)
+	for feature, value := range point {
+		spanned[feature] = pointRange(value)
+	}
+	return spanned
+}
+
+func pointRange(value interface{}) interface{} {
+	if category, ok := value.(string); ok {
+		return Categories{category}
+	}
+	return [2]interface{}{value, value}
+}
+
+// holds reports whether b holds point on every feature point has a value of,
+// and point has a value of any.
+func (b box) holds(point map[*Node]interface{}) bool {
+	compared := false
+	for feature, rangeValue := range b {
+		if value, ok := point[feature]; ok {
+			if !holdsValue(rangeValue, value) {
+				return false
+			}
+			compared = true
+		}
+	}
+	return compared
+}
+
+// meets reports whether b and other overlap on every feature both have a
+// range of.
+func (b box) meets(other box) bool {
+	for feature, rangeValue := range b {
+		if otherValue, ok := other[feature]; ok && !rangesMeet(rangeValue, otherValue) {
+			return false
+		}
+	}
+	return len(b) > 0 && len(other) > 0
+}
+
+func holdsValue(rangeValue interface{}, value interface{}) bool {
+	if categories, ok := rangeValue.(Categories); ok {
+		category, _ := value.(string)
+		return categories.contains(category)
+	}
+	min, max, ok := boundsLimits(rangeValue)
+	number, err := convertToFloat64(value)
+	return ok && err == nil && number >= min && number <= max
+}
+
+func rangesMeet(first interface{}, second interface{}) bool {
+	if categories, ok := first.(Categories); ok {
+		for _, category := range categories {
+			if holdsValue(second, category) {
+				return true
+			}
+		}
+		return false
+	}
+	min, max, ok := boundsLimits(first)
+	otherMin, otherMax, otherOk := boundsLimits(second)
+	return ok && otherOk && min <= otherMax && otherMin <= max
+}
+
+func (graph *graphIndex) boxOf(combination *Node) box {
+	spanned := make(box)
+	for _, connection := range combination.Connections {
+		if connection.Node.Type == Range {
+			spanned[graph.rangeFeatures[connection.Node]] = connection.Node.Value
+		}
+	}
+	return spanned
+}
+
+// setBox sets the ranges of combination to the values b holds for their
+// features.
+func (graph *graphIndex) setBox(combination *Node, b box) {
+	for _, connection := range combination.Connections {
+		if connection.Node.Type != Range {
+			continue
+		}
+		if value, ok := b[graph.rangeFeatures[connection.Node]]; ok {
+			connection.Node.Value = value
+			graph.syncRangeValues(connection.Node)
+		}
+	}
+}
+
+// representPoint cuts point out of the combinations of other classes holding
+// it and connects object, whose values point holds, to the combinations of
+// its class holding it, widening or seeding one when none does. points holds
+// the values of the objects whose values the model keeps.
+func (asonn *Asonn) representPoint(object *Node, point map[*Node]interface{}, points map[*Node]map[*Node]interface{}) {
+	class := getClassOfObject(object)
+	represented := false
+	var stranded []*Node
+	for _, combination := range append([]*Node(nil), asonn.graph().nodes(Combination)...) {
+		if !asonn.graph().boxOf(combination).holds(point) {
+			continue
+		}
+		if getClassOfObject(combination) == class {
+			addConnection(combination, object, 1)
+			represented = true
+		} else {
+			stranded = append(stranded, asonn.cutCombination(combination, pointBox(point), points)...)
+		}
+	}
+	if !represented {
+		asonn.widenOrSeed(object, point, points)
+	}
+	for _, seed := range stranded {
+		asonn.representObject(seed, points)
+	}
+}
+
+// representObject connects a known object to a combination of its class
+// unless it is represented already.
+func (asonn *Asonn) representObject(object *Node, points map[*Node]map[*Node]interface{}) {
+	class := getClassOfObject(object)
+	for _, connection := range object.Connections {
+		if connection.Node.Type == Combination && getClassOfObject(connection.Node) == class {
+			return
+		}
+	}
+	for _, combination := range asonn.graph().nodes(Combination) {
+		if getClassOfObject(combination) == class && asonn.graph().boxOf(combination).holds(points[object]) {
+			addConnection(combination, object, 1)
+			return
+		}
+	}
+	asonn.widenOrSeed(object, points[object], points)
+}
+
+// cutCombination cuts hole out of combination along the feature that leaves
+// fewest known objects of the combination outside its parts, then gives fewest
+// parts and then keeps most of the combination. The combination keeps the
+// part representing most objects and the other parts become new combinations,
+// except for parts without objects when the model knows the values of its
+// objects. A combination nothing is left of is removed. Known objects left
+// outside the parts are returned.
+func (asonn *Asonn) cutCombination(combination *Node, hole box, points map[*Node]map[*Node]interface{}) []*Node {
+	graph := asonn.graph()
+	current := graph.boxOf(combination)
+	var members []*Node
+	for _, connection := range combination.Connections {
+		if connection.Node.Type == Object && !containsNode(members, connection.Node) {
+			members = append(members, connection.Node)
+		}
+	}
+	var cut *Node
+	var parts []interface{}
+	var stranded []*Node
+	kept := -1.0
+	for _, feature := range graph.nodes(Feature) {
+		rangeValue, ok := current[feature]
+		holeValue, inHole := hole[feature]
+		if !ok || !inHole {
+			continue
+		}
+		featureParts := graph.cutOut(feature, rangeValue, holeValue)
+		if len(featureParts) == 0 {
+			continue
+		}
+		var left []*Node
+		for _, member := range members {
+			if value, known := points[member][feature]; known && holdsValue(holeValue, value) {
+				left = append(left, member)
+			}
+		}
+		share := keptShare(rangeValue, featureParts)
+		if cut == nil || len(left) < len(stranded) || len(left) == len(stranded) && (len(featureParts) < len(parts) || len(featureParts) == len(parts) && share > kept) {
+			cut, parts, stranded, kept = feature, featureParts, left, share
+		}
+	}
+	dropEmpty := len(points) > 0
+	partMembers := make([][]*Node, len(parts))
+	for _, member := range members {
+		value, known := points[member][cut]
+		for i, part := range parts {
+			if !known || holdsValue(part, value) {
+				partMembers[i] = append(partMembers[i], member)
+			}
+		}
+	}
+	keep := -1
+	for i := range parts {
+		if (!dropEmpty || len(partMembers[i]) > 0) && (keep < 0 || len(partMembers[i]) > len(partMembers[keep])) {
+			keep = i
+		}
+	}
+	if keep < 0 {
+		var lost []*Node
+		for _, member := range members {
+			if points[member] != nil {
+				lost = append(lost, member)
+			}
+		}
+		asonn.removeCombinations(combination)
+		return lost
+	}
+	var features []*Node
+	weights := make(map[*Node]float64)
+	for _, connection := range combination.Connections {
+		if connection.Node.Type == Range {
+			features = append(features, graph.rangeFeatures[connection.Node])
+			weights[graph.rangeFeatures[connection.Node]] = connection.Weight
+		}
+	}
+	for i, part := range parts {
+		if i == keep || dropEmpty && len(partMembers[i]) == 0 {
+			continue
+		}
+		partBox := make(box)
+		for feature, rangeValue := range current {
+			partBox[feature] = rangeValue
+		}
+		partBox[cut] = part
+		asonn.addBox(classNodeOf(combination), features, partBox, weights, partMembers[i])
+	}
+	for _, member := range members {
+		if !containsNode(partMembers[keep], member) {
+			removeConnection(combination, member)
+		}
+	}
+	current[cut] = parts[keep]
+	graph.setBox(combination, current)
+	return stranded
+}
+
+// cutOut returns the parts of rangeValue, a range of feature, left when hole
+// is cut out of it.
+func (graph *graphIndex) cutOut(feature *Node, rangeValue interface{}, hole interface{}) []interface{} {
+	if categories, ok := rangeValue.(Categories); ok {
+		var left Categories
+		for _, category := range categories {
+			if !holdsValue(hole, category) {
+				left = append(left, category)
+			}
+		}
+		if len(left) == 0 {
+			return nil
+		}
+		return []interface{}{left}
+	}
+	min, max, ok := boundsLimits(rangeValue)
+	holeMin, holeMax, holeOk := boundsLimits(hole)
+	if !ok || !holeOk {
+		return nil
+	}
+	bounds := rangeValue.([2]interface{})
+	var parts []interface{}
+	if below := graph.beside(feature, holeMin, -1); min < holeMin && below >= min {
+		parts = append(parts, [2]interface{}{bounds[0], below})
+	}
+	if above := graph.beside(feature, holeMax, 1); holeMax < max && above <= max {
+		parts = append(parts, [2]interface{}{above, bounds[1]})
+	}
+	return parts
+}
+
+// beside returns the value of feature next to value in direction: the
+// nearest value the model keeps, the neighbouring integer in integer and
+// ordinal columns or the neighbouring float.
+func (graph *graphIndex) beside(feature *Node, value float64, direction float64) float64 {
+	nearest := math.Inf(int(direction))
+	for _, valueNode := range graph.featureValues[feature] {
+		if other := graph.valueFloats[valueNode]; (other-value)*direction > 0 && (other-nearest)*direction < 0 {
+			nearest = other
+		}
+	}
+	if !math.IsInf(nearest, 0) {
+		return nearest
+	}
+	if column, ok := graph.columns[feature]; ok && (column.Type == IntegerColumn || column.Type == OrdinalColumn) {
+		return value + direction
+	}
+	return math.Nextafter(value, nearest)
+}
+
+// keptShare is the share of rangeValue its parts keep.
+func keptShare(rangeValue interface{}, parts []interface{}) float64 {
+	if categories, ok := rangeValue.(Categories); ok {
+		kept := 0
+		for _, part := range parts {
+			kept += len(part.(Categories))
+		}
+		return float64(kept) / float64(len(categories))
+	}
+	min, max, _ := boundsLimits(rangeValue)
+	if max == min {
+		return 0
+	}
+	kept := 0.0
+	for _, part := range parts {
+		partMin, partMax, _ := boundsLimits(part)
+		kept += partMax - partMin
+	}
+	return kept / (max - min)
+}
+
+// widenOrSeed widens the combination of the class of object that needs the
+// smallest expansion to hold point, provided it takes in no combination or
+// known object of another class it didn't reach before, and seeds a
+// combination spanning point when none can be widened.
+func (asonn *Asonn) widenOrSeed(object *Node, point map[*Node]interface{}, points map[*Node]map[*Node]interface{}) {
+	graph := asonn.graph()
+	class := getClassOfObject(object)
+	extents := graph.rangeExtents()
+	var best *Node
+	var bestBox box
+	bestCost := math.Inf(1)
+	for _, combination := range graph.nodes(Combination) {
+		if getClassOfObject(combination) != class {
+			continue
+		}
+		current := graph.boxOf(combination)
+		widened, cost := widen(current, point, extents)
+		if cost < bestCost && asonn.canWiden(class, current, widened, points) {
+			best, bestBox, bestCost = combination, widened, cost
+		}
+	}
+	if best == nil {
+		asonn.seedPoint(object, point)
+		return
+	}
+	graph.setBox(best, bestBox)
+	addConnection(best, object, 1)
+	for _, other := range graph.nodes(Object) {
+		if other != object && getClassOfObject(other) == class && points[other] != nil && !areConnected(best, other) && bestBox.holds(points[other]) {
+			addConnection(best, other, 1)
+		}
+	}
+}
+
+// widen returns b widened to hold point with the cost of widening: the growth
+// of every numeric range over the extent of the ranges of its feature and 1
+// for every category taken in.
+func widen(b box, point map[*Node]interface{}, extents map[*Node]float64) (box, float64) {
+	widened := make(box, len(b))
+	cost := 0.0
+	for feature, rangeValue := range b {
+		widened[feature] = rangeValue
+		value, ok := point[feature]
+		if !ok || holdsValue(rangeValue, value) {
+			continue
+		}
+		if categories, ok := rangeValue.(Categories); ok {
+			category, _ := value.(string)
+			widened[feature] = categories.with(category)
+			cost++
+			continue
+		}
+		bounds, ok := rangeValue.([2]interface{})
+		min, max, limited := boundsLimits(rangeValue)
+		number, err := convertToFloat64(value)
+		if !ok || !limited || err != nil {
+			return nil, math.Inf(1)
+		}
+		extent := extents[feature]
+		if extent == 0 {
+			extent = 1
+		}
+		if number < min {
+			bounds[0] = value
+			cost += (min - number) / extent
+		} else {
+			bounds[1] = value
+			cost += (number - max) / extent
+		}
+		widened[feature] = bounds
+	}
+	return widened, cost
+}
+
+// canWiden reports whether widened, a combination of class widened from
+// current, takes in no combination or known object of another class that
+// current didn't reach.
+func (asonn *Asonn) canWiden(class string, current box, widened box, points map[*Node]map[*Node]interface{}) bool {
+	graph := asonn.graph()
+	for _, combination := range graph.nodes(Combination) {
+		if getClassOfObject(combination) == class {
+			continue
+		}
+		if other := graph.boxOf(combination); widened.meets(other) && !current.meets(other) {
+			return false
+		}
+	}
+	for object, point := range points {
+		if getClassOfObject(object) != class && widened.holds(point) && !current.holds(point) {
+			return false
+		}
+	}
+	return true
+}
+
+// rangeExtents returns the distance between the lowest and the highest range
+// end of every numeric feature.
+func (graph *graphIndex) rangeExtents() map[*Node]float64 {
+	extents := make(map[*Node]float64)
+	for feature, ranges := range graph.featureRanges {
+		low, high := math.Inf(1), math.Inf(-1)
+		for _, rangeNode := range ranges {
+			if min, max, ok := rangeLimits(rangeNode); ok {
+				low, high = math.Min(low, min), math.Max(high, max)
+			}
+		}
+		if high > low {
+			extents[feature] = high - low
+		}
+	}
+	return extents
+}
+
+// seedPoint adds a combination of the class of object spanning point alone.
+func (asonn *Asonn) seedPoint(object *Node, point map[*Node]interface{}) {
+	var features []*Node
+	for _, feature := range asonn.graph().nodes(Feature) {
+		if _, ok := point[feature]; ok {
+			features = append(features, feature)
+		}
+	}
+	asonn.addBox(classNodeOf(object), features, pointBox(point), nil, []*Node{object})
+}
+
+// addBox adds a combination of classNode with ranges of b in the order of
+// features, weighted by weights or evenly when weights is nil, and
+// representing members.
+func (asonn *Asonn) addBox(classNode *Node, features []*Node, b box, weights map[*Node]float64, members []*Node) *Node {
+	graph := asonn.graph()
+	combinationNode := NewNode(asonn.nextCombinationName(), Combination)
+	var newNodes []*Node
+	for _, feature := range features {
+		rangeNode := NewNode(b[feature], Range)
+		addConnection(&combinationNode, &rangeNode, 1)
+		if weights != nil {
+			combinationNode.Connections[len(combinationNode.Connections)-1].Weight = weights[feature]
+		} else {
+			combinationNode.Connections[len(combinationNode.Connections)-1].Weight = 1 / float64(len(features))
+		}
+		addConnection(&rangeNode, feature, 1)
+		graph.syncRangeValues(&rangeNode)
+		newNodes = append(newNodes, &rangeNode)
+	}
+	addConnection(&combinationNode, classNode, 1)
+	for _, member := range members {
+		addConnection(member, &combinationNode, 1)
+	}
+	asonn.addNodes(append(newNodes, &combinationNode)...)
+	return &combinationNode
+}
+
+// removeCombinations removes combinations together with their ranges.
+func (asonn *Asonn) removeCombinations(combinations ...*Node) {
+	removed := make(map[*Node]bool)
+	for _, combination := range combinations {
+		for _, connection := range append(ConnectionSlice(nil), combination.Connections...) {
+			if connection.Node.Type == Range {
+				removed[connection.Node] = true
+				isolate(connection.Node)
+			}
+		}
+		removed[combination] = true
+		isolate(combination)
+	}
+	asonn.Nodes = withoutNodes(asonn.Nodes, removed)
+	asonn.Reindex()
+}
+
+// objectValues returns the value of every feature of the objects whose Value
+// nodes are in the graph.
+func (asonn *Asonn) objectValues() map[*Node]map[*Node]interface{} {
+	graph := asonn.graph()
+	points := make(map[*Node]map[*Node]interface{})
+	for _, object := range graph.nodes(Object) {
+		if point := graph.pointOf(object); len(point) > 0 {
+			points[object] = point
+		}
+	}
+	return points
+}
+
+func (graph *graphIndex) pointOf(object *Node) map[*Node]interface{} {
+	point := make(map[*Node]interface{})
+	for _, connection := range object.Connections {
+		if feature, ok := graph.valueFeatures[connection.Node]; ok {
+			point[feature] = connection.Node.Value
+		}
+	}
+	return point
+}
+
+// keepsValues reports whether Value nodes of the objects are part of the
+// graph, as they are in multi-layer models.
+func (graph *graphIndex) keepsValues() bool {
+	return len(graph.nodes(Value)) > 0
+}
+
+// layerChange is an object added to or removed from a multi-layer model.
+type layerChange struct {
+	class string
+	point map[*Node]interface{}
+}
+
+// refreshLayers updates the combinations of a multi-layer model after the
+// objects of changes were added or removed. A combination spans the objects
+// of its class within its parent, so only combinations of a changed class
+// within a parent holding the change are recomputed, and their
+// sub-combinations are rebuilt only when their ranges change.
+func (asonn *Asonn) refreshLayers(changes []layerChange) error {
+	graph := asonn.graph()
+	children := make(map[*Node]bool)
+	for _, combination := range graph.nodes(Combination) {
+		for _, child := range subCombinations(combination) {
+			children[child] = true
+		}
+	}
+	var tops []*Node
+	for _, combination := range graph.nodes(Combination) {
+		if !children[combination] && !containsNode(tops, combination) {
+			tops = append(tops, combination)
+		}
+	}
+	classNodes := append([]*Node(nil), graph.nodes(Class)...)
+	err := asonn.refreshCombinations(nil, tops, classNodes, changes)
+	asonn.Reindex()
+	return err
+}
+
+// refreshCombinations updates combinations, the sub-combinations of parent or
+// the top-level ones when parent is nil, after the objects of changes within
+// parent were added or removed.
+func (asonn *Asonn) refreshCombinations(parent *Node, combinations []*Node, classNodes []*Node, changes []layerChange) error {
+	byClass := make(map[string]*Node)
+	for _, combination := range combinations {
+		class := classNodeOf(combination)
+		if class == nil || !containsNode(classNodes, class) {
+			asonn.removeLayer(parent, combination)
+			continue
+		}
+		byClass[classLabel(class)] = combination
+	}
+	for i, classNode := range classNodes {
+		label := classLabel(classNode)
+		if parent != nil && getClassOfObject(parent) == label {
+			continue
+		}
+		combination := byClass[label]
+		changed := false
+		for _, change := range changes {
+			changed = changed || change.class == label
+		}
+		if changed {
+			spanned, err := asonn.graph().classBox(classNode, parent)
+			if err != nil {
+				return err
+			}
+			if combination == nil || !reflect.DeepEqual(asonn.graph().boxOf(combination), spanned) {
+				if combination != nil {
+					asonn.removeLayer(parent, combination)
+				}
+				if spanned == nil {
+					continue
+				}
+				if err := asonn.addLayer(parent, classNodes, i); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if combination == nil {
+			continue
+		}
+		var inside []layerChange
+		spanned := asonn.graph().boxOf(combination)
+		for _, change := range changes {
+			if spanned.holds(change.point) {
+				inside = append(inside, change)
+			}
+		}
+		if len(inside) > 0 {
+			if err := asonn.refreshCombinations(combination, subCombinations(combination), classNodes, inside); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// classBox returns the ranges spanning the objects of classNode within
+// parent, or all of them when parent is nil, and nil when there are none.
+func (graph *graphIndex) classBox(classNode *Node, parent *Node) (box, error) {
+	values := make(map[*Node][]interface{})
+	for _, connection := range classNode.Connections {
+		object := connection.Node
+		if object.Type != Object {
+			continue
+		}
+		if parent != nil {
+			within, err := object.isWithinCombination(parent)
+			if err != nil {
+				return nil, err
+			}
+			if !within {
+				continue
+			}
+		}
+		for _, objectConnection := range object.Connections {
+			if feature, ok := graph.valueFeatures[objectConnection.Node]; ok {
+				values[feature] = append(values[feature], objectConnection.Node.Value)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	spanned := make(box)
+	for feature, featureValues := range values {
+		rangeNode := NewNode(featureValues, Range)
+		if err := reduceRange(&rangeNode); err != nil {
+			return nil, err
+		}
+		spanned[feature] = rangeNode.Value
+	}
+	return spanned, nil
+}
+
+// addLayer adds the combination of classNodes[i] within parent, a top-level
+// one when parent is nil, with its sub-combinations.
+func (asonn *Asonn) addLayer(parent *Node, classNodes []*Node, i int) error {
+	combination, ranges, ok, err := newClassCombination(classNodes, i, parent)
+	if err != nil || !ok {
+		return err
+	}
+	if parent != nil {
+		addOneWayConnection(parent, combination)
+	}
+	asonn.addNodes(append(ranges, combination)...)
+	return asonn.addSublayers(classNodes, []*Node{combination})
+}
+
+// removeLayer removes combination, a sub-combination of parent or a top-level
+// one when parent is nil, with its ranges and sub-combinations.
+func (asonn *Asonn) removeLayer(parent *Node, combination *Node) {
+	if parent != nil {
+		parent.Connections = withoutNode(parent.Connections, combination)
+	}
+	removed := []*Node{combination}
+	for i := 0; i < len(removed); i++ {
+		for _, child := range subCombinations(removed[i]) {
+			removed[i].Connections = withoutNode(removed[i].Connections, child)
+			removed = append(removed, child)
+		}
+	}
+	asonn.removeCombinations(removed...)
+}
+
+// subCombinations returns the combinations combination inhibits.
+func subCombinations(combination *Node) []*Node {
+	var children []*Node
+	for _, connection := range combination.Connections {
+		if connection.Node.Type == Combination && !areConnected(connection.Node, combination) {
+			children = append(children, connection.Node)
+		}
+	}
+	return children
+}
+
+func (asonn *Asonn) nextObjectID() int {
+	next := 1
+	for _, object := range append(append([]*Node(nil), asonn.Nodes...), asonn.samples...) {
+		if object.Type != Object {
+			continue
+		}
+		name, _ := object.Value.(string)
+		if id, err := strconv.Atoi(strings.TrimPrefix(name, "O")); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return next
+}
+
+// nextCombinationName names a new combination after the highest numbered
+// one, as Forget can leave gaps.
+func (asonn *Asonn) nextCombinationName() string {
+	next := 0
+	for _, combination := range asonn.graph().nodes(Combination) {
+		name, _ := combination.Value.(string)
+		if id, err := strconv.Atoi(strings.TrimPrefix(name, "C")); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return "C" + strconv.Itoa(next)
+}
+
+// syncRangeValues connects rangeNode to exactly the values of its feature
+// it holds.
+func (graph *graphIndex) syncRangeValues(rangeNode *Node) {
+	for _, connection := range append(ConnectionSlice(nil), rangeNode.Connections...) {
+		if connection.Node.Type == Value && !holdsValue(rangeNode.Value, connection.Node.Value) {
+			removeConnection(rangeNode, connection.Node)
+		}
+	}
+	feature, err := getFeatureConnection(rangeNode)
+	if err != nil {
+		return
+	}
+	for _, valueNode := range graph.featureValues[feature] {
+		if holdsValue(rangeNode.Value, valueNode.Value) && !areConnected(rangeNode, valueNode) {
+			addConnection(rangeNode, valueNode, 1)
+		}
+	}
+}
+
+// rangeLimits returns the numeric ends of a reduced range.
+func rangeLimits(node *Node) (float64, float64, bool) {
+	return boundsLimits(node.Value)
+}
+
+func boundsLimits(value interface{}) (float64, float64, bool) {
+	bounds, ok := value.([2]interface{})
+	if !ok {
+		return 0, 0, false
+	}
+	min, minErr := convertToFloat64(bounds[0])
+	max, maxErr := convertToFloat64(bounds[1])
+	return min, max, minErr == nil && maxErr == nil
+}
+
+// sampleValue converts a value of a new row to the type of the feature values
+// in models without a schema.
+func sampleValue(values []*Node, strValue string) (interface{}, error) {
+	value := convertToCorrectType(strValue)
+	if integer, ok := value.(int); ok {
+		value = float64(integer)
+	}
+	if len(values) == 0 {
+		return value, nil
+	}
+	if _, ok := values[0].Value.(string); ok {
+		return strValue, nil
+	}
+	if _, ok := values[0].Value.(float64); ok {
+		number, err := convertToFloat64(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrNonNumericRange, strValue)
+		}
+		return number, nil
+	}
+	return value, nil
+}
+
+func findValue(values []*Node, value interface{}) *Node {
+	for _, valueNode := range values {
+		if valueNode.Value == value {
+			return valueNode
+		}
+	}
+	return nil
+}
+
+// insertValue connects a new Value node to feature, keeping the Value
+// connections of the feature in descending order.
+func insertValue(feature *Node, valueNode *Node) {
+	newVal, numeric := valueNode.Value.(float64)
+	position := 0
+	for position < len(feature.Connections) && feature.Connections[position].Node.Type == Value {
+		if val, ok := feature.Connections[position].Node.Value.(float64); numeric && ok && val < newVal {
+			break
+		}
+		position++
+	}
+	feature.Connections = append(feature.Connections, Connection{})
+	copy(feature.Connections[position+1:], feature.Connections[position:])
+	feature.Connections[position] = NewConnection(valueNode, 1)
+	valueNode.Connections = append(valueNode.Connections, NewConnection(feature, 1))
+}
+
+// unlinkValues removes ASIM connections between values.
+func unlinkValues(values []*Node) {
+	for _, valueNode := range values {
+		var connections ConnectionSlice
+		for _, connection := range valueNode.Connections {
+			if connection.Node.Type != Value {
+				connections = append(connections, connection)
+			}
+		}
+		valueNode.Connections = connections
+	}
+}
+
+func removeConnection(first *Node, second *Node) {
+	first.Connections = withoutNode(first.Connections, second)
+	second.Connections = withoutNode(second.Connections, first)
+}
+
+func withoutNode(connections ConnectionSlice, node *Node) ConnectionSlice {
+	var filtered ConnectionSlice
+	for _, connection := range connections {
+		if connection.Node != node {
+			filtered = append(filtered, connection)
+		}
+	}
+	return filtered
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
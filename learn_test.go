@@ -0,0 +1,121 @@
+package gasonn
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func accuracy(tb testing.TB, asonn *Asonn, x [][]string, y []string) float64 {
+	tb.Helper()
+	predictions, err := asonn.Classify(x)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	correct := 0
+	for i, prediction := range predictions {
+		if prediction.Label == y[i+1] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(predictions))
+}
+
+func TestLearn(t *testing.T) {
+	x, y := loadFixture(t, "iris")
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		batch, err := Train(x, y, WithStrategy(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		initialX, initialY := [][]string{x[0]}, []string{y[0]}
+		var rest []int
+		for i := 1; i < len(x); i++ {
+			if i%5 == 1 {
+				initialX = append(initialX, x[i])
+				initialY = append(initialY, y[i])
+			} else {
+				rest = append(rest, i)
+			}
+		}
+		incremental, err := Train(initialX, initialY, WithStrategy(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, i := range rest {
+			if err := incremental.Learn(x[i], y[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, want := accuracy(t, incremental, x, y), accuracy(t, batch, x, y); got < want {
+			t.Errorf("Strategy %d: incremental accuracy %f is below batch accuracy %f", strategy, got, want)
+		}
+	}
+}
+
+func TestLearnNewClass(t *testing.T) {
+	asonn, err := Train(trainX, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := []string{"5.0", "5.0"}
+	if err := asonn.Learn(row, "q"); err != nil {
+		t.Fatal(err)
+	}
+	x := append(append([][]string{}, trainX...), row)
+	y := append(append([]string{}, trainY...), "q")
+	if got := accuracy(t, asonn, x, y); got != 1 {
+		t.Errorf("Accuracy %f after learning a new class", got)
+	}
+}
+
+func TestLearnAfterLoad(t *testing.T) {
+	asonn, err := Train(trainX[:5], trainY[:5])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err := asonn.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 5; i < len(trainX); i++ {
+		if err := loaded.Learn(trainX[i], trainY[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := accuracy(t, loaded, trainX, trainY); got != 1 {
+		t.Errorf("Accuracy %f after learning a loaded model", got)
+	}
+}
+
+type learnErrorTestData struct {
+	name  string
+	row   []string
+	label string
+	err   error
+}
+
+var learnErrorTests = []learnErrorTestData{
+	{"short row", []string{"1.0"}, "p", ErrRaggedRow},
+	{"missing label", []string{"1.0", "2.0"}, "", ErrMissingLabel},
+	{"non numeric", []string{"x", "2.0"}, "p", ErrInvalidValue},
+}
+
+func TestLearnErrors(t *testing.T) {
+	asonn, err := Train(trainX, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, testData := range learnErrorTests {
+		if err := asonn.Learn(testData.row, testData.label); !errors.Is(err, testData.err) {
+			t.Errorf("%s: got error %v instead of %v", testData.name, err, testData.err)
+		}
+	}
+	if err := (&Asonn{}).Learn(nil, "p"); !errors.Is(err, ErrNoSamples) {
+		t.Errorf("Got error %v instead of %v", err, ErrNoSamples)
+	}
+}
@@ -0,0 +1,134 @@
+package gasonn
+
+import (
+	"encoding/gob"
+	"math"
+)
+
+// MembershipFunc computes the activation of a Range node. spread is the width
+// over which the activation falls off outside of [min, max], ActivationScale
+// times the width of the range, so it is 0 for ranges of a single value.
+// Implementations must be registered with gob.Register to be saved.
+type MembershipFunc interface {
+	Activation(value, min, max, spread float64) float64
+	// Support returns the span outside of which the activation is below
+	// 1e-9, so ranges can be skipped during inference.
+	Support(min, max, spread float64) (float64, float64)
+}
+
+// Crisp activates a range fully within it and not at all outside of it.
+type Crisp struct{}
+
+// GaussianTail activates a range fully within it and with a Gaussian tail
+// outside of it. It is the membership of the ASONN paper.
+type GaussianTail struct{}
+
+// Triangular peaks in the middle of a range and falls linearly to 0 at half
+// the spread beyond its ends.
+type Triangular struct{}
+
+// Trapezoidal activates fully on the middle Plateau fraction of a range and
+// falls linearly to 0 at half the spread beyond its ends. A Plateau of 0 is
+// Triangular.
+type Trapezoidal struct {
+	Plateau float64
+}
+
+// LinearDecay activates a range fully within it and falls linearly to 0 at a
+// whole spread beyond its ends.
+type LinearDecay struct{}
+
+func init() {
+	gob.Register(Crisp{})
+	gob.Register(GaussianTail{})
+	gob.Register(Triangular{})
+	gob.Register(Trapezoidal{})
+	gob.Register(LinearDecay{})
+}
+
+func (Crisp) Activation(value, min, max, spread float64) float64 {
+	if value >= min && value <= max {
+		return 1.0
+	}
+	return 0.0
+}
+
+func (Crisp) Support(min, max, spread float64) (float64, float64) {
+	return min, max
+}
+
+func (GaussianTail) Activation(value, min, max, spread float64) float64 {
+	if value >= min && value <= max {
+		return 1.0
+	}
+	if spread == 0 {
+		return 0.0
+	}
+	return math.Pow(math.E, (1-math.Pow((2*value-max-min)/spread, 2))/2)
+}
+
+func (GaussianTail) Support(min, max, spread float64) (float64, float64) {
+	mid, half := (min+max)/2, spread/2*activationTail
+	return math.Min(min, mid-half), math.Max(max, mid+half)
+}
+
+func (Triangular) Activation(value, min, max, spread float64) float64 {
+	return Trapezoidal{}.Activation(value, min, max, spread)
+}
+
+func (Triangular) Support(min, max, spread float64) (float64, float64) {
+	return min - spread/2, max + spread/2
+}
+
+func (trapezoidal Trapezoidal) Activation(value, min, max, spread float64) float64 {
+	if spread == 0 {
+		return Crisp{}.Activation(value, min, max, spread)
+	}
+	plateau := math.Max(0, math.Min(1, trapezoidal.Plateau))
+	mid := (min + max) / 2
+	top := (max - min) / 2 * plateau
+	foot := (max-min)/2 + spread/2
+	return linearFall(math.Abs(value-mid), top, foot)
+}
+
+func (Trapezoidal) Support(min, max, spread float64) (float64, float64) {
+	return min - spread/2, max + spread/2
+}
+
+func (LinearDecay) Activation(value, min, max, spread float64) float64 {
+	if spread == 0 {
+		return Crisp{}.Activation(value, min, max, spread)
+	}
+	mid := (min + max) / 2
+	return linearFall(math.Abs(value-mid), (max-min)/2, (max-min)/2+spread)
+}
+
+func (LinearDecay) Support(min, max, spread float64) (float64, float64) {
+	return min - spread, max + spread
+}
+
+// linearFall is 1 up to distance top, 0 from distance foot on and linear in
+// between.
+func linearFall(distance, top, foot float64) float64 {
+	switch {
+	case distance <= top:
+		return 1.0
+	case distance >= foot:
+		return 0.0
+	default:
+		return (foot - distance) / (foot - top)
+	}
+}
+
+// membership returns the membership function of feature.
+func (config Config) membership(feature *Node) MembershipFunc {
+	if name, ok := feature.Value.(string); ok {
+		if membership, ok := config.FeatureMembership[name]; ok && membership != nil {
+			return membership
+		}
+	}
+	if config.Membership != nil {
+		return config.Membership
+	}
+	return GaussianTail{}
+}
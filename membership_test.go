@@ -0,0 +1,99 @@
+package gasonn
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type membershipTestData struct {
+	name       string
+	membership MembershipFunc
+	value      float64
+	min        float64
+	max        float64
+	spread     float64
+	activation float64
+}
+
+var membershipTests = []membershipTestData{
+	{"crisp inside", Crisp{}, 1.5, 1, 2, 1, 1},
+	{"crisp outside", Crisp{}, 2.1, 1, 2, 1, 0},
+	{"gaussian inside", GaussianTail{}, 1.5, 1, 2, 1, 1},
+	{"gaussian at spread", GaussianTail{}, 2.5, 1, 2, 1, math.Exp(-1.5)},
+	{"triangular middle", Triangular{}, 1.5, 1, 2, 1, 1},
+	{"triangular end", Triangular{}, 2, 1, 2, 1, 0.5},
+	{"triangular foot", Triangular{}, 2.5, 1, 2, 1, 0},
+	{"trapezoidal plateau", Trapezoidal{Plateau: 0.5}, 1.7, 1, 2, 1, 1},
+	{"trapezoidal shoulder", Trapezoidal{Plateau: 0.5}, 2.125, 1, 2, 1, 0.5},
+	{"linear inside", LinearDecay{}, 2, 1, 2, 1, 1},
+	{"linear outside", LinearDecay{}, 2.25, 1, 2, 1, 0.75},
+	{"linear foot", LinearDecay{}, 3, 1, 2, 1, 0},
+}
+
+func TestMembership(t *testing.T) {
+	for _, testData := range membershipTests {
+		if activation := testData.membership.Activation(testData.value, testData.min, testData.max, testData.spread); math.Abs(activation-testData.activation) > 1e-12 {
+			t.Errorf("%s: activation %f instead of %f", testData.name, activation, testData.activation)
+		}
+	}
+}
+
+var memberships = []MembershipFunc{Crisp{}, GaussianTail{}, Triangular{}, Trapezoidal{Plateau: 0.5}, LinearDecay{}}
+
+func TestMembershipDegenerateRange(t *testing.T) {
+	for _, membership := range memberships {
+		for _, value := range []float64{-1, 2, 2.5} {
+			activation := membership.Activation(value, 2, 2, 0)
+			if want := (Crisp{}).Activation(value, 2, 2, 0); activation != want {
+				t.Errorf("%T: activation %f at %f of a single value range", membership, activation, value)
+			}
+		}
+	}
+}
+
+func TestMembershipSupport(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, membership := range memberships {
+		low, high := membership.Support(1, 2, 1)
+		for i := 0; i < 1000; i++ {
+			value := random.Float64()*10 - 4
+			if activation := membership.Activation(value, 1, 2, 1); (value < low || value > high) && activation >= negligibleActivation {
+				t.Fatalf("%T: activation %g at %f outside support [%f, %f]", membership, activation, value, low, high)
+			}
+		}
+	}
+}
+
+func TestFeatureMembership(t *testing.T) {
+	asonn, err := Train(trainX, trainY, WithMembership(Crisp{}), WithFeatureMembership("b", Triangular{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph := asonn.graph()
+	for _, feature := range graph.nodes(Feature) {
+		want := MembershipFunc(Crisp{})
+		if feature.Value == "b" {
+			want = Triangular{}
+		}
+		if graph.memberships[feature] != want {
+			t.Errorf("Feature %v uses %T instead of %T", feature.Value, graph.memberships[feature], want)
+		}
+	}
+	if got := asonn.Predict([][]string{trainX[0], {"9.0", "9.0"}})[0]; got != 0 {
+		t.Errorf("Activation %f far from crisp and triangular ranges", got)
+	}
+	var buffer bytes.Buffer
+	if err := asonn.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.config(), asonn.config()) {
+		t.Errorf("Loaded configuration %+v instead of %+v", loaded.config(), asonn.config())
+	}
+}
@@ -0,0 +1,525 @@
+package gasonn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrStrategyMismatch = errors.New("Models were trained with different strategies")
+	ErrFeatureMismatch  = errors.New("Models have different features")
+	ErrConfigMismatch   = errors.New("Models were trained with different configurations")
+)
+
+// Merge builds a model from the graphs and training samples of two trained
+// models. Feature and Class nodes are unified by name and the objects of b
+// join those of a. Where single-layer combinations of different classes from
+// a and b overlap, the one expected to have fewer of its seeds in the overlap
+// has the ranges of the other cut out of it. Overlapping combinations of the
+// same class are then joined when their joint ranges reach no combination of
+// another class that neither reached, and range weights are recomputed from
+// the objects of both models. Multi-layer combinations are recomputed from
+// the objects of both models where b changes them. a and b are not modified.
+func Merge(a, b *Asonn) (*Asonn, error) {
+	if a.IsRegression() || b.IsRegression() {
+		return nil, ErrRegression
+	}
+	if !reflect.DeepEqual(a.config(), b.config()) {
+		return nil, ErrConfigMismatch
+	}
+	merged, err := a.clone()
+	if err != nil {
+		return nil, err
+	}
+	other, err := b.clone()
+	if err != nil {
+		return nil, err
+	}
+	merged.Calibration = nil
+	if merged.Schema, err = mergeSchemas(a.Schema, b.Schema); err != nil {
+		return nil, err
+	}
+	multiLayer := merged.graph().keepsValues()
+	if multiLayer != other.graph().keepsValues() {
+		return nil, ErrStrategyMismatch
+	}
+	if len(merged.graph().nodes(Combination)) == 0 || len(other.graph().nodes(Combination)) == 0 {
+		return nil, ErrNoSamples
+	}
+	if !multiLayer {
+		for _, model := range []*Asonn{merged, other} {
+			if len(model.samples) == 0 {
+				return nil, ErrNoSamples
+			}
+			model.Nodes = append(model.Nodes, model.samples...)
+			model.Reindex()
+		}
+	}
+	var objects []*Node
+	fromB := make(map[*Node]bool)
+	if multiLayer {
+		for _, object := range other.graph().nodes(Object) {
+			if !containsNode(objects, object) {
+				objects = append(objects, object)
+			}
+		}
+		other.removeCombinations(other.graph().nodes(Combination)...)
+	}
+	for _, combination := range other.graph().nodes(Combination) {
+		fromB[combination] = true
+	}
+	if err := merged.graft(other); err != nil {
+		return nil, err
+	}
+	if multiLayer {
+		var changes []layerChange
+		for _, object := range objects {
+			changes = append(changes, layerChange{class: getClassOfObject(object), point: merged.graph().pointOf(object)})
+		}
+		if err := merged.refreshLayers(changes); err != nil {
+			return nil, err
+		}
+		return merged, nil
+	}
+	merged.resolveConflicts(fromB)
+	merged.joinCombinations()
+	for i, combination := range merged.graph().nodes(Combination) {
+		combination.Value = "C" + strconv.Itoa(i)
+	}
+	merged.updateRangeToCombinationConnectionWeights()
+	merged.removeValueAndObjectNodes()
+	return merged, nil
+}
+
+// Shard is the labelled data a model was trained on, in the form Train
+// accepts.
+type Shard struct {
+	X [][]string
+	Y []string
+}
+
+// MergeShards merges a and b like Merge and returns the accuracy of the
+// merged model on the data of each, shardA of a and shardB of b. Merge never
+// reads the shards; where they can't be brought together, call Accuracy on
+// the merged model next to each of them instead.
+func MergeShards(a, b *Asonn, shardA, shardB Shard) (*Asonn, [2]float64, error) {
+	var accuracies [2]float64
+	merged, err := Merge(a, b)
+	if err != nil {
+		return nil, accuracies, err
+	}
+	for i, shard := range []Shard{shardA, shardB} {
+		if accuracies[i], err = merged.Accuracy(shard.X, shard.Y); err != nil {
+			return nil, accuracies, err
+		}
+	}
+	return merged, accuracies, nil
+}
+
+// Accuracy returns the share of labelled rows of x classified as their label
+// in y, using the same header conventions as Train.
+func (asonn *Asonn) Accuracy(x [][]string, y []string) (float64, error) {
+	if asonn.IsRegression() {
+		return 0, ErrRegression
+	}
+	if err := validate(x, y); err != nil {
+		return 0, err
+	}
+	predictions, err := asonn.Classify(x)
+	if err != nil {
+		return 0, err
+	}
+	correct, labelled := 0, 0
+	for i, prediction := range predictions {
+		if y[i+1] == "" {
+			continue
+		}
+		labelled++
+		if prediction.Label == y[i+1] {
+			correct++
+		}
+	}
+	if labelled == 0 {
+		return 0, nil
+	}
+	return float64(correct) / float64(labelled), nil
+}
+
+// Samples returns the training objects a model keeps, in the form Train
+// accepts: feature names in the first row of x and an empty first label.
+func (asonn *Asonn) Samples() ([][]string, []string) {
+	nodes := append(append([]*Node(nil), asonn.Nodes...), asonn.samples...)
+	graph := newGraphIndex(nodes, asonn.config(), asonn.Schema)
+	features := graph.nodes(Feature)
+	header := make([]string, len(features))
+	for j, feature := range features {
+		header[j] = fmt.Sprint(feature.Value)
+	}
+	x, y := [][]string{header}, []string{""}
+	seen := make(map[*Node]bool)
+	for _, object := range graph.nodes(Object) {
+		if seen[object] {
+			continue
+		}
+		seen[object] = true
+		row := make([]string, len(features))
+		for _, connection := range object.Connections {
+			if feature, ok := graph.valueFeatures[connection.Node]; ok {
+				for j := range features {
+					if features[j] == feature {
+						row[j] = graph.columns[feature].format(connection.Node.Value)
+					}
+				}
+			}
+		}
+		x = append(x, row)
+		y = append(y, getClassOfObject(object))
+	}
+	return x, y
+}
+
+// formatSampleValue keeps a trailing ".0" on whole floats, so the value is
+// read back with its type.
+func formatSampleValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		formatted := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.ContainsAny(formatted, ".NI") {
+			formatted += ".0"
+		}
+		return formatted
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (asonn *Asonn) clone() (*Asonn, error) {
+	var buffer bytes.Buffer
+	if err := asonn.Save(&buffer); err != nil {
+		return nil, err
+	}
+	return Load(&buffer)
+}
+
+// graft moves the nodes of other into asonn. Features, classes and values
+// asonn already has replace their counterparts and objects of other are
+// renamed after the objects of asonn.
+func (asonn *Asonn) graft(other *Asonn) error {
+	graph, otherGraph := asonn.graph(), other.graph()
+	if len(graph.nodes(Feature)) != len(otherGraph.nodes(Feature)) {
+		return fmt.Errorf("%w: %d and %d features", ErrFeatureMismatch, len(graph.nodes(Feature)), len(otherGraph.nodes(Feature)))
+	}
+	mapped := make(map[*Node]*Node)
+	used := make(map[interface{}]int)
+	for _, feature := range otherGraph.nodes(Feature) {
+		candidates := graph.features[feature.Value]
+		if used[feature.Value] >= len(candidates) {
+			return fmt.Errorf("%w: %v", ErrFeatureMismatch, feature.Value)
+		}
+		mapped[feature] = candidates[used[feature.Value]]
+		used[feature.Value]++
+		if err := unifyValueTypes(graph, mapped[feature], otherGraph, feature); err != nil {
+			return err
+		}
+		for _, valueNode := range otherGraph.featureValues[feature] {
+			if existing := findValue(graph.featureValues[mapped[feature]], valueNode.Value); existing != nil {
+				mapped[valueNode] = existing
+			}
+		}
+	}
+	for _, class := range otherGraph.nodes(Class) {
+		if existing, reused := tryToReuseClassNode(class.Value, graph.nodes(Class)); reused {
+			mapped[class] = existing
+		}
+	}
+	var adopted []*Node
+	isAdopted := make(map[*Node]bool)
+	for _, node := range other.Nodes {
+		if _, ok := mapped[node]; !ok && !isAdopted[node] {
+			isAdopted[node] = true
+			adopted = append(adopted, node)
+		}
+	}
+	next := asonn.nextObjectID()
+	for _, node := range adopted {
+		if node.Type == Object {
+			node.Value = "O" + strconv.Itoa(next)
+			next++
+		}
+		var connections ConnectionSlice
+		for _, connection := range node.Connections {
+			target, ok := mapped[connection.Node]
+			switch {
+			case node.Type == Value && (connection.Node.Type == Value || connection.Node.Type == Feature):
+				// ASIM links are rebuilt and values are inserted in order below.
+			case node.Type == Range && connection.Node.Type == Value:
+				// Ranges are connected to the joined values below.
+			case ok:
+				connections = append(connections, NewConnection(target, connection.Weight))
+				target.Connections = append(target.Connections, NewConnection(node, connection.Weight))
+			case isAdopted[connection.Node]:
+				connections = append(connections, connection)
+			}
+		}
+		node.Connections = connections
+	}
+	for _, node := range adopted {
+		if node.Type == Value {
+			insertValue(mapped[otherGraph.valueFeatures[node]], node)
+		}
+	}
+	asonn.Nodes = append(asonn.Nodes, adopted...)
+	asonn.Reindex()
+	graph = asonn.graph()
+	for _, feature := range graph.nodes(Feature) {
+		unlinkValues(graph.featureValues[feature])
+		if len(graph.featureValues[feature]) > 0 {
+			linkValues(graph.featureValues[feature])
+		}
+	}
+	for _, object := range graph.nodes(Object) {
+		weighObject(object)
+	}
+	for _, rangeNode := range graph.nodes(Range) {
+		graph.syncRangeValues(rangeNode)
+	}
+	return nil
+}
+
+// mergeSchemas joins the schemas of two models. A column read as integer by
+// one and as numeric by the other is numeric, and imputed values of the first
+// model are kept.
+func mergeSchemas(schema, other Schema) (Schema, error) {
+	if schema == nil || other == nil {
+		if schema == nil {
+			return other, nil
+		}
+		return schema, nil
+	}
+	if len(schema) != len(other) {
+		return nil, fmt.Errorf("%w: %d and %d columns", ErrFeatureMismatch, len(schema), len(other))
+	}
+	merged := make(Schema, len(schema))
+	seen := make(map[string]int)
+	for j, column := range schema {
+		otherColumn, ok := other.nthColumn(column.Name, seen[column.Name])
+		seen[column.Name]++
+		otherColumn.Fill = column.Fill
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: %s", ErrFeatureMismatch, column.Name)
+		case column.Missing != otherColumn.Missing:
+			return nil, fmt.Errorf("%w: %s has different missing value policies", ErrFeatureMismatch, column.Name)
+		case reflect.DeepEqual(column, otherColumn):
+			merged[j] = column
+		case column.Type == IntegerColumn && otherColumn.Type == NumericColumn, column.Type == NumericColumn && otherColumn.Type == IntegerColumn:
+			merged[j] = Column{Name: column.Name, Type: NumericColumn, Missing: column.Missing, Fill: column.Fill}
+		default:
+			return nil, fmt.Errorf("%w: %s is %v and %v", ErrFeatureMismatch, column.Name, column.Type, otherColumn.Type)
+		}
+	}
+	return merged, nil
+}
+
+// unifyValueTypes checks that a feature holds values of the same kind in
+// both models.
+func unifyValueTypes(graph *graphIndex, feature *Node, otherGraph *graphIndex, otherFeature *Node) error {
+	kind, otherKind := featureKind(graph, feature), featureKind(otherGraph, otherFeature)
+	if kind != otherKind && kind != "" && otherKind != "" {
+		return fmt.Errorf("%w: %v holds %s and %s values", ErrFeatureMismatch, feature.Value, kind, otherKind)
+	}
+	return nil
+}
+
+func featureKind(graph *graphIndex, feature *Node) string {
+	var value interface{}
+	switch {
+	case len(graph.featureValues[feature]) > 0:
+		value = graph.featureValues[feature][0].Value
+	case len(graph.featureRanges[feature]) > 0:
+		value = graph.featureRanges[feature][0].Value
+	default:
+		return ""
+	}
+	switch value.(type) {
+	case string, Categories:
+		return "string"
+	default:
+		return "float"
+	}
+}
+
+// joinCombinations joins overlapping combinations of the same class while
+// their joint ranges reach no combination of another class that neither of
+// them reached.
+func (asonn *Asonn) joinCombinations() {
+	for joined := true; joined; {
+		joined = false
+		combinations := asonn.graph().nodes(Combination)
+		for i := 0; i < len(combinations) && !joined; i++ {
+			for j := i + 1; j < len(combinations) && !joined; j++ {
+				joined = asonn.joinCombination(combinations[i], combinations[j])
+			}
+		}
+	}
+}
+
+func (asonn *Asonn) joinCombination(first *Node, second *Node) bool {
+	graph := asonn.graph()
+	class := getClassOfObject(first)
+	if getClassOfObject(second) != class {
+		return false
+	}
+	firstBox, secondBox := graph.boxOf(first), graph.boxOf(second)
+	if len(firstBox) != len(secondBox) || !firstBox.meets(secondBox) {
+		return false
+	}
+	joint := make(box)
+	for feature, rangeValue := range firstBox {
+		otherValue, ok := secondBox[feature]
+		if !ok {
+			return false
+		}
+		if joint[feature] = span(rangeValue, otherValue); joint[feature] == nil {
+			return false
+		}
+	}
+	if !asonn.canWiden(class, firstBox, joint, nil) || !asonn.canWiden(class, secondBox, joint, nil) {
+		return false
+	}
+	graph.setBox(first, joint)
+	for _, connection := range second.Connections {
+		if connection.Node.Type == Object && !areConnected(first, connection.Node) {
+			addConnection(first, connection.Node, 1)
+		}
+	}
+	asonn.removeCombinations(second)
+	return true
+}
+
+// span returns the range holding both ranges, nil when they are of different
+// kinds.
+func span(first interface{}, second interface{}) interface{} {
+	if categories, ok := first.(Categories); ok {
+		otherCategories, ok := second.(Categories)
+		if !ok {
+			return nil
+		}
+		for _, category := range otherCategories {
+			categories = categories.with(category)
+		}
+		return categories
+	}
+	bounds, ok := first.([2]interface{})
+	otherBounds, otherOk := second.([2]interface{})
+	min, max, limited := boundsLimits(first)
+	otherMin, otherMax, otherLimited := boundsLimits(second)
+	if !ok || !otherOk || !limited || !otherLimited {
+		return nil
+	}
+	if otherMin < min {
+		bounds[0] = otherBounds[0]
+	}
+	if otherMax > max {
+		bounds[1] = otherBounds[1]
+	}
+	return bounds
+}
+
+// resolveConflicts cuts overlaps between combinations of different classes
+// where one comes from b, as fromB tells. Seeds are taken to spread evenly
+// over the ranges of their combination, so the one expected to have fewer
+// seeds in the overlap, and thus fewer weeds for the other, has the ranges
+// of the other cut out of it. The combination of a wins ties.
+func (asonn *Asonn) resolveConflicts(fromB map[*Node]bool) {
+	for {
+		winner, loser := asonn.conflict(fromB)
+		if winner == nil {
+			return
+		}
+		graph := asonn.graph()
+		winnerBox, loserBox := graph.boxOf(winner), graph.boxOf(loser)
+		if float64(seedCount(winner))*winnerBox.overlapShare(loserBox) < float64(seedCount(loser))*loserBox.overlapShare(winnerBox) {
+			winner, loser = loser, winner
+		}
+		before := make(map[*Node]bool)
+		for _, combination := range asonn.graph().nodes(Combination) {
+			before[combination] = true
+		}
+		asonn.cutCombination(loser, asonn.graph().boxOf(winner), nil)
+		for _, combination := range asonn.graph().nodes(Combination) {
+			if !before[combination] {
+				fromB[combination] = fromB[loser]
+			}
+		}
+	}
+}
+
+// conflict returns overlapping combinations of different classes, one of a
+// and one of b, or nils when there are none.
+func (asonn *Asonn) conflict(fromB map[*Node]bool) (*Node, *Node) {
+	graph := asonn.graph()
+	combinations := graph.nodes(Combination)
+	for _, first := range combinations {
+		if fromB[first] {
+			continue
+		}
+		for _, second := range combinations {
+			if fromB[second] && getClassOfObject(first) != getClassOfObject(second) && graph.boxOf(first).meets(graph.boxOf(second)) {
+				return first, second
+			}
+		}
+	}
+	return nil, nil
+}
+
+// overlapShare is the share of b that other overlaps, feature by feature.
+// A range holding a single value is all within any range it meets.
+func (b box) overlapShare(other box) float64 {
+	share := 1.0
+	for feature, rangeValue := range b {
+		otherValue, ok := other[feature]
+		if !ok {
+			continue
+		}
+		if categories, ok := rangeValue.(Categories); ok {
+			shared := 0
+			for _, category := range categories {
+				if holdsValue(otherValue, category) {
+					shared++
+				}
+			}
+			share *= float64(shared) / float64(len(categories))
+			continue
+		}
+		min, max, _ := boundsLimits(rangeValue)
+		otherMin, otherMax, _ := boundsLimits(otherValue)
+		overlap := math.Min(max, otherMax) - math.Max(min, otherMin)
+		switch {
+		case overlap < 0:
+			return 0
+		case max > min:
+			share *= overlap / (max - min)
+		}
+	}
+	return share
+}
+
+// seedCount is the number of objects of its class combination represents.
+func seedCount(combination *Node) int {
+	class := getClassOfObject(combination)
+	var seeds []*Node
+	for _, connection := range combination.Connections {
+		if connection.Node.Type == Object && getClassOfObject(connection.Node) == class && !containsNode(seeds, connection.Node) {
+			seeds = append(seeds, connection.Node)
+		}
+	}
+	return len(seeds)
+}
@@ -0,0 +1,162 @@
+package gasonn
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	x, y := loadFixture(t, "iris")
+	shardsX := [][][]string{{x[0]}, {x[0]}}
+	shardsY := [][]string{{y[0]}, {y[0]}}
+	for i := 1; i < len(x); i++ {
+		shardsX[i%2] = append(shardsX[i%2], x[i])
+		shardsY[i%2] = append(shardsY[i%2], y[i])
+	}
+	for _, test := range []struct {
+		strategy Strategy
+		accuracy float64
+	}{{SingleLayer, 0.85}, {MultiLayer, 0.9}} {
+		a, err := Train(shardsX[0], shardsY[0], WithStrategy(test.strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Train(shardsX[1], shardsY[1], WithStrategy(test.strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes := len(a.Nodes) + len(b.Nodes)
+		merged, accuracies, err := MergeShards(a, b, Shard{shardsX[0], shardsY[0]}, Shard{shardsX[1], shardsY[1]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(a.Nodes)+len(b.Nodes) != nodes {
+			t.Errorf("Strategy %d: Merge modified its arguments", test.strategy)
+		}
+		for i := range shardsX {
+			if got := accuracy(t, merged, shardsX[i], shardsY[i]); got < test.accuracy || got != accuracies[i] {
+				t.Errorf("Strategy %d: accuracy %f on shard %d, reported %f", test.strategy, got, i, accuracies[i])
+			}
+		}
+		// Multi-layer combinations are those of a model trained on both shards,
+		// single-layer ones generalise better than those of either model.
+		want := math.Nextafter(math.Max(accuracy(t, a, x, y), accuracy(t, b, x, y)), 1)
+		if test.strategy == MultiLayer {
+			batch, err := Train(x, y, WithStrategy(MultiLayer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want = accuracy(t, batch, x, y)
+		}
+		if got := accuracy(t, merged, x, y); got < want {
+			t.Errorf("Strategy %d: accuracy %f on the data of both models instead of %f", test.strategy, got, want)
+		}
+		objects := make(map[*Node]bool)
+		for _, node := range append(append([]*Node(nil), merged.Nodes...), merged.samples...) {
+			if node.Type == Object {
+				objects[node] = true
+			}
+		}
+		if len(objects) != len(x)-1 {
+			t.Errorf("Strategy %d: merged model keeps %d objects instead of %d", test.strategy, len(objects), len(x)-1)
+		}
+		if samplesX, _ := merged.Samples(); len(samplesX) != len(x) {
+			t.Errorf("Strategy %d: merged model keeps %d samples instead of %d", test.strategy, len(samplesX)-1, len(x)-1)
+		}
+		if test.strategy == SingleLayer {
+			assertRangeWeights(t, merged)
+		}
+	}
+}
+
+// assertRangeWeights checks that range weights of the combinations of a
+// single-layer model are those training computes from its samples.
+func assertRangeWeights(t *testing.T, asonn *Asonn) {
+	t.Helper()
+	weights := make(map[*Node][]float64)
+	for _, node := range asonn.Nodes {
+		for _, connection := range node.Connections {
+			if node.Type == Combination && connection.Node.Type == Range {
+				weights[node] = append(weights[node], connection.Weight)
+			}
+		}
+	}
+	asonn.Nodes = append(asonn.Nodes, asonn.samples...)
+	asonn.Reindex()
+	asonn.updateRangeToCombinationConnectionWeights()
+	asonn.removeValueAndObjectNodes()
+	for combination, want := range weights {
+		i := 0
+		for _, connection := range combination.Connections {
+			if connection.Node.Type != Range {
+				continue
+			}
+			if math.Abs(connection.Weight-want[i]) > 1e-9 {
+				t.Errorf("Combination %v has range weight %f instead of %f", combination.Value, want[i], connection.Weight)
+			}
+			i++
+		}
+	}
+}
+
+func TestMergeCategorical(t *testing.T) {
+	a, err := Train(categoricalX[:5], categoricalY[:5])
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Train(append([][]string{categoricalX[0]}, categoricalX[5:]...), append([]string{categoricalY[0]}, categoricalY[5:]...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, err := Merge(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := accuracy(t, merged, categoricalX, categoricalY); got != 1 {
+		t.Errorf("Accuracy %f on the data of both models", got)
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	single, err := Train(trainX, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multi, err := Train(trainX, trainY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := append([][]string{{"a", "c"}}, trainX[1:]...)
+	other, err := Train(renamed, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		b    *Asonn
+		err  error
+	}{
+		{"strategies", multi, ErrStrategyMismatch},
+		{"features", other, ErrFeatureMismatch},
+		{"no samples", &Asonn{}, ErrNoSamples},
+	}
+	for _, test := range tests {
+		if _, err := Merge(single, test.b); !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v instead of %v", test.name, err, test.err)
+		}
+	}
+}
+func TestSamples(t *testing.T) {
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		asonn, err := Train(trainX, trainY, WithStrategy(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y := asonn.Samples()
+		if !reflect.DeepEqual(x, trainX) || !reflect.DeepEqual(y[1:], trainY[1:]) {
+			t.Errorf("Strategy %d: got samples %v %v", strategy, x, y)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package gasonn
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// MissingCategory is the category missing cells become under
+// MissingAsCategory.
+const MissingCategory = "?"
+
+// MissingPolicy tells how empty and "?" cells of a column are handled.
+type MissingPolicy int
+
+const (
+	// SkipMissing leaves the object without a value of the feature and the
+	// feature out of inference, normalizing activations by the features present.
+	SkipMissing MissingPolicy = iota
+	// ImputeMean fills in the mean of the column, rounded for integer and
+	// ordinal columns and the mode for categorical ones.
+	ImputeMean
+	// ImputeMedian fills in the median of the column and the mode for
+	// categorical columns.
+	ImputeMedian
+	// ImputeMode fills in the most frequent value of the column.
+	ImputeMode
+	// ImputeAssociative fills in the value of the most similar training
+	// object that has one, and the mode when none does.
+	ImputeAssociative
+	// MissingAsCategory reads missing cells of categorical columns as
+	// MissingCategory. Other columns skip them.
+	MissingAsCategory
+)
+
+// WithMissing sets the missing value policy of columns the schema given by
+// WithSchema doesn't name, SkipMissing by default.
+func WithMissing(policy MissingPolicy) Option {
+	return func(o *options) {
+		o.missing = policy
+	}
+}
+
+func isMissing(cell string) bool {
+	return cell == "" || cell == MissingCategory
+}
+
+// skips reports whether cell is left out under the policy of the column.
+func (column Column) skips(cell string) bool {
+	return isMissing(cell) && !(column.Missing == MissingAsCategory && column.Type == CategoricalColumn)
+}
+
+// imputation returns the value Fill of the column takes from its present
+// labelled cells.
+func (column Column) imputation(cells []string) string {
+	if len(cells) == 0 {
+		return ""
+	}
+	var positions []float64
+	if column.Type != CategoricalColumn {
+		for _, cell := range cells {
+			value, err := column.parse(cell)
+			if err != nil {
+				return mode(cells)
+			}
+			position, _ := convertToFloat64(value)
+			positions = append(positions, position)
+		}
+	}
+	var value float64
+	switch {
+	case column.Type == CategoricalColumn || column.Missing == ImputeMode || column.Missing == ImputeAssociative:
+		return mode(cells)
+	case column.Missing == ImputeMean:
+		for _, position := range positions {
+			value += position
+		}
+		value /= float64(len(positions))
+	default:
+		sort.Float64s(positions)
+		middle := len(positions) / 2
+		value = positions[middle]
+		if len(positions)%2 == 0 {
+			value = (positions[middle-1] + positions[middle]) / 2
+		}
+	}
+	switch column.Type {
+	case IntegerColumn:
+		return strconv.Itoa(int(math.Round(value)))
+	case OrdinalColumn:
+		return column.Order[int(math.Round(value))]
+	default:
+		return formatSampleValue(value)
+	}
+}
+
+// mode returns the most frequent cell, the first seen on ties.
+func mode(cells []string) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, cell := range cells {
+		counts[cell]++
+		if counts[cell] > counts[best] || best == "" {
+			best = cell
+		}
+	}
+	return best
+}
+
+// fillMissing returns x with missing cells of imputed columns filled in.
+// neighbours holds the rows associative imputation copies from, and both have
+// feature names in the first row. Rows are copied only when a cell changes.
+func (schema Schema) fillMissing(x [][]string, neighbours [][]string) [][]string {
+	var filled [][]string
+	var spans map[int]float64
+	columns := schema.columnsOf(x[0])
+	for i := 1; i < len(x); i++ {
+		row := x[i]
+		if len(row) != len(x[0]) {
+			continue
+		}
+		copied := false
+		for j := range x[0] {
+			column, ok := columns[j]
+			if !ok || !isMissing(row[j]) || column.Missing == SkipMissing || column.Missing == MissingAsCategory {
+				continue
+			}
+			if filled == nil {
+				filled = append([][]string(nil), x...)
+			}
+			if !copied {
+				filled[i] = append([]string(nil), x[i]...)
+				copied = true
+			}
+			filled[i][j] = column.Fill
+			if column.Missing == ImputeAssociative && len(neighbours) > 1 {
+				if spans == nil {
+					spans = schema.spans(neighbours)
+				}
+				if cell, ok := associate(x[0], columns, row, j, neighbours, spans); ok {
+					filled[i][j] = cell
+				}
+			}
+		}
+	}
+	if filled == nil {
+		return x
+	}
+	return filled
+}
+
+// associate returns cell j of the neighbour most similar to row over the
+// features both have, among neighbours that have it. columns holds the column
+// of every cell of row and spans those of the cells of the neighbours.
+func associate(header []string, columns map[int]Column, row []string, j int, neighbours [][]string, spans map[int]float64) (string, bool) {
+	neighbourIndex := make([]int, len(header))
+	seen := make(map[string]int)
+	for k, name := range header {
+		neighbourIndex[k] = nthIndexOf(neighbours[0], name, seen[name])
+		seen[name]++
+	}
+	if neighbourIndex[j] < 0 {
+		return "", false
+	}
+	best, bestSimilarity := "", -1.0
+	for _, neighbour := range neighbours[1:] {
+		if len(neighbour) != len(neighbours[0]) {
+			continue
+		}
+		cell := neighbour[neighbourIndex[j]]
+		if isMissing(cell) {
+			continue
+		}
+		similarity := 0.0
+		for k := range header {
+			if k == j || neighbourIndex[k] < 0 || isMissing(row[k]) || isMissing(neighbour[neighbourIndex[k]]) {
+				continue
+			}
+			similarity += columns[k].similarity(row[k], neighbour[neighbourIndex[k]], spans[neighbourIndex[k]])
+		}
+		if similarity > bestSimilarity {
+			best, bestSimilarity = cell, similarity
+		}
+	}
+	return best, bestSimilarity >= 0
+}
+
+// spans returns the difference between the highest and the lowest value of
+// every ordered column of rows, by position.
+func (schema Schema) spans(rows [][]string) map[int]float64 {
+	spans := make(map[int]float64)
+	columns := schema.columnsOf(rows[0])
+	for j := range rows[0] {
+		column, ok := columns[j]
+		if !ok || column.Type == CategoricalColumn {
+			continue
+		}
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, row := range rows[1:] {
+			if len(row) != len(rows[0]) || isMissing(row[j]) {
+				continue
+			}
+			if value, err := column.parse(row[j]); err == nil {
+				position, _ := convertToFloat64(value)
+				min, max = math.Min(min, position), math.Max(max, position)
+			}
+		}
+		if max > min {
+			spans[j] = max - min
+		}
+	}
+	return spans
+}
+
+// similarity is 1 for equal cells and falls linearly with the distance of
+// ordered values over span.
+func (column Column) similarity(cell string, other string, span float64) float64 {
+	if cell == other {
+		return 1.0
+	}
+	if column.Type == CategoricalColumn || span == 0 {
+		return 0.0
+	}
+	value, err := column.parse(cell)
+	otherValue, otherErr := column.parse(other)
+	if err != nil || otherErr != nil {
+		return 0.0
+	}
+	position, _ := convertToFloat64(value)
+	otherPosition, _ := convertToFloat64(otherValue)
+	return 1 - math.Abs(position-otherPosition)/span
+}
+
+// normalize scales combination activations of a row with skipped missing
+// features up to all features.
+func (graph *graphIndex) normalize(state activations, skipped int) {
+	featuresNumber := graph.featuresNumber()
+	if skipped == 0 || float64(skipped) >= featuresNumber {
+		return
+	}
+	for _, combination := range graph.nodes(Combination) {
+		state[combination] *= featuresNumber / (featuresNumber - float64(skipped))
+	}
+}
+
+// fillMissing imputes missing cells of test, whose first row holds feature
+// names, from the training samples the model keeps.
+func (asonn *Asonn) fillMissing(test [][]string) [][]string {
+	var neighbours [][]string
+	for _, name := range test[0] {
+		if column, ok := asonn.Schema.column(name); ok && column.Missing == ImputeAssociative {
+			neighbours, _ = asonn.Samples()
+			break
+		}
+	}
+	return asonn.Schema.fillMissing(test, neighbours)
+}
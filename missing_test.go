@@ -0,0 +1,123 @@
+package gasonn
+
+import "testing"
+
+var missingX = [][]string{
+	{"a", "b", "colour"},
+	{"1.0", "2.5", "red"},
+	{"1.2", "", "red"},
+	{"3.1", "0.5", "?"},
+	{"3.3", "0.7", "blue"},
+	{"", "2.6", "red"},
+	{"3.0", "?", "blue"},
+	{"1.1", "2.4", ""},
+}
+
+var missingY = []string{"target", "p", "p", "n", "n", "p", "n", "p"}
+
+type missingTestData struct {
+	policy MissingPolicy
+	row    []string
+	label  string
+}
+
+var missingTests = []missingTestData{
+	{SkipMissing, []string{"3.2", "", "blue"}, "n"},
+	{ImputeMean, []string{"1.1", "", "red"}, "p"},
+	{ImputeMedian, []string{"", "0.6", "blue"}, "n"},
+	{ImputeMode, []string{"1.1", "2.5", "?"}, "p"},
+	{ImputeAssociative, []string{"", "0.6", ""}, "n"},
+	{MissingAsCategory, []string{"3.2", "0.6", "?"}, "n"},
+}
+
+func TestMissing(t *testing.T) {
+	for _, testData := range missingTests {
+		for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+			asonn, err := Train(missingX, missingY, WithStrategy(strategy), WithMissing(testData.policy))
+			if err != nil {
+				t.Fatalf("Policy %d, strategy %d: %v", testData.policy, strategy, err)
+			}
+			nodes := append(append([]*Node(nil), asonn.Nodes...), asonn.samples...)
+			graph := newGraphIndex(nodes, asonn.config(), asonn.Schema)
+			for _, feature := range graph.nodes(Feature) {
+				for _, valueNode := range graph.featureValues[feature] {
+					_, numeric := valueNode.Value.(float64)
+					if feature.Value != "colour" && !numeric || valueNode.Value == "" {
+						t.Errorf("Policy %d, strategy %d: %v has value %q", testData.policy, strategy, feature.Value, valueNode.Value)
+					}
+					if valueNode.Value == MissingCategory && testData.policy != MissingAsCategory {
+						t.Errorf("Policy %d, strategy %d: missing colour is a category", testData.policy, strategy)
+					}
+				}
+			}
+			if strategy == SingleLayer {
+				continue
+			}
+			if got := accuracy(t, asonn, missingX, missingY); got != 1 {
+				t.Errorf("Policy %d: accuracy %f on the training data", testData.policy, got)
+			}
+			predictions, err := asonn.Classify([][]string{missingX[0], testData.row})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if predictions[0].Label != testData.label {
+				t.Errorf("Policy %d: %v classified as %s instead of %s", testData.policy, testData.row, predictions[0].Label, testData.label)
+			}
+		}
+	}
+}
+
+func TestMissingNormalization(t *testing.T) {
+	asonn, err := Train(missingX, missingY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	predictions, err := asonn.Classify([][]string{missingX[0], {"1.1", "2.5", "red"}, {"1.1", "", "red"}, {"", "", ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if complete, partial := predictions[0].Scores["p"], predictions[1].Scores["p"]; complete != partial {
+		t.Errorf("Score %f with a missing feature instead of %f", partial, complete)
+	}
+	if score := predictions[2].Scores["p"]; score != 0 {
+		t.Errorf("Score %f without features", score)
+	}
+	if got := asonn.Predict([][]string{missingX[0], {"1.1", "", "red"}})[0]; got != predictions[1].Scores["p"] {
+		t.Errorf("Predict activation %f instead of %f", got, predictions[1].Scores["p"])
+	}
+}
+
+type imputationTestData struct {
+	column Column
+	cells  []string
+	fill   string
+}
+
+var imputationTests = []imputationTestData{
+	{Column{Type: NumericColumn, Missing: ImputeMean}, []string{"1.0", "2.0", "6.0"}, "3.0"},
+	{Column{Type: NumericColumn, Missing: ImputeMedian}, []string{"1.0", "2.0", "6.0", "8.0"}, "4.0"},
+	{Column{Type: IntegerColumn, Missing: ImputeMean}, []string{"1", "2", "2"}, "2"},
+	{Column{Type: OrdinalColumn, Order: []string{"low", "medium", "high"}, Missing: ImputeMedian}, []string{"low", "high", "high"}, "high"},
+	{Column{Type: CategoricalColumn, Missing: ImputeMean}, []string{"red", "blue", "blue"}, "blue"},
+	{Column{Type: NumericColumn, Missing: ImputeMode}, []string{"1.0", "2.0", "1.0"}, "1.0"},
+	{Column{Type: NumericColumn, Missing: ImputeMean}, nil, ""},
+}
+
+func TestImputation(t *testing.T) {
+	for _, testData := range imputationTests {
+		if fill := testData.column.imputation(testData.cells); fill != testData.fill {
+			t.Errorf("%v of %v is %q instead of %q", testData.column.Missing, testData.cells, fill, testData.fill)
+		}
+	}
+}
+
+func TestAssociativeImputation(t *testing.T) {
+	schema := Schema{{Name: "a", Type: NumericColumn, Missing: ImputeAssociative, Fill: "1.0"}, {Name: "b", Type: NumericColumn, Missing: ImputeAssociative, Fill: "2.5"}}
+	filled := schema.fillMissing([][]string{{"a", "b"}, {"", "0.68"}, {"1.0", "2.5"}}, missingX)
+	if filled[1][0] != "3.3" {
+		t.Errorf("Imputed %q instead of the value of the most similar object", filled[1][0])
+	}
+	if filled[2][0] != "1.0" || filled[2][1] != "2.5" {
+		t.Errorf("Complete row changed to %v", filled[2])
+	}
+}
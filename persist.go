@@ -0,0 +1,268 @@
+package gasonn
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	modelFormat        = "gasonn"
+	modelFormatVersion = 5
+)
+
+var (
+	ErrInvalidModel       = errors.New("Not a gasonn model")
+	ErrUnsupportedVersion = errors.New("Unsupported model format version")
+)
+
+// savedModel is the on-disk representation of an Asonn. Nodes are stored once
+// in a table and connections refer to them by index, so cycles are never followed.
+type savedModel struct {
+	Format  string
+	Version int
+	Nodes   []savedNode
+	Order   []int
+	Edges   []savedEdge
+	// Calibration was added in version 2.
+	Calibration *Calibration
+	// Samples lists Value and Object nodes kept out of Nodes by single-layer
+	// training, added in version 3.
+	Samples []int
+	// Config was added in version 4.
+	Config *Config
+	// Schema was added in version 5.
+	Schema Schema
+}
+
+type savedNode struct {
+	Type  string
+	Value savedValue
+}
+
+type savedEdge struct {
+	From   int
+	To     int
+	Weight float64
+}
+
+type valueKind int
+
+const (
+	stringKind valueKind = iota
+	intKind
+	floatKind
+	rangeKind
+	listKind
+	categoriesKind
+)
+
+type savedValue struct {
+	Kind   valueKind
+	String string
+	Int    int
+	Float  float64
+	Values []savedValue
+}
+
+// Save writes the model graph to w. Only nodes in asonn.Nodes, the training
+// samples needed by Learn and the connections between them are written.
+func (asonn *Asonn) Save(w io.Writer) error {
+	model := savedModel{Format: modelFormat, Version: modelFormatVersion, Calibration: asonn.Calibration, Config: asonn.Config, Schema: asonn.Schema}
+	ids := make(map[*Node]int)
+	nodeID := func(node *Node) (int, error) {
+		id, ok := ids[node]
+		if !ok {
+			value, err := encodeValue(node.Value)
+			if err != nil {
+				return 0, err
+			}
+			id = len(model.Nodes)
+			ids[node] = id
+			model.Nodes = append(model.Nodes, savedNode{Type: node.Type, Value: value})
+		}
+		return id, nil
+	}
+	for _, node := range asonn.Nodes {
+		id, err := nodeID(node)
+		if err != nil {
+			return err
+		}
+		model.Order = append(model.Order, id)
+	}
+	for _, node := range asonn.samples {
+		id, err := nodeID(node)
+		if err != nil {
+			return err
+		}
+		model.Samples = append(model.Samples, id)
+	}
+	written := make([]bool, len(model.Nodes))
+	for _, node := range append(append([]*Node(nil), asonn.Nodes...), asonn.samples...) {
+		from := ids[node]
+		if written[from] {
+			continue
+		}
+		written[from] = true
+		for _, connection := range node.Connections {
+			if to, ok := ids[connection.Node]; ok {
+				model.Edges = append(model.Edges, savedEdge{From: from, To: to, Weight: connection.Weight})
+			}
+		}
+	}
+	return gob.NewEncoder(w).Encode(model)
+}
+
+// Load reads a model written by Save.
+func Load(r io.Reader) (*Asonn, error) {
+	var model savedModel
+	if err := gob.NewDecoder(r).Decode(&model); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModel, err)
+	}
+	if model.Format != modelFormat {
+		return nil, ErrInvalidModel
+	}
+	if model.Version < 1 || model.Version > modelFormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, model.Version)
+	}
+	nodes := make([]*Node, len(model.Nodes))
+	for i, saved := range model.Nodes {
+		value, err := decodeValue(saved.Value)
+		if err != nil {
+			return nil, err
+		}
+		if saved.Type == Value || saved.Type == Range {
+			value = promoteIntegers(value)
+		}
+		node := NewNode(value, saved.Type)
+		nodes[i] = &node
+	}
+	for _, edge := range model.Edges {
+		if edge.From < 0 || edge.From >= len(nodes) || edge.To < 0 || edge.To >= len(nodes) {
+			return nil, fmt.Errorf("%w: edge %d-%d out of range", ErrInvalidModel, edge.From, edge.To)
+		}
+		nodes[edge.From].Connections = append(nodes[edge.From].Connections, NewConnection(nodes[edge.To], edge.Weight))
+	}
+	if err := model.Schema.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModel, err)
+	}
+	if model.Config != nil && model.Config.EstimateShare == 0 {
+		// Models saved before Config had EstimateShare decode it as zero.
+		model.Config.EstimateShare = DefaultConfig().EstimateShare
+	}
+	asonn := &Asonn{Calibration: model.Calibration, Config: model.Config, Schema: model.Schema}
+	for _, id := range model.Order {
+		if id < 0 || id >= len(nodes) {
+			return nil, fmt.Errorf("%w: node %d out of range", ErrInvalidModel, id)
+		}
+		asonn.Nodes = append(asonn.Nodes, nodes[id])
+	}
+	for _, id := range model.Samples {
+		if id < 0 || id >= len(nodes) {
+			return nil, fmt.Errorf("%w: node %d out of range", ErrInvalidModel, id)
+		}
+		asonn.samples = append(asonn.samples, nodes[id])
+	}
+	asonn.Reindex()
+	return asonn, nil
+}
+
+// promoteIntegers turns int values and range ends, which integer columns
+// were read as before they were kept as float64, into float64.
+func promoteIntegers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case [2]interface{}:
+		return [2]interface{}{promoteIntegers(v[0]), promoteIntegers(v[1])}
+	case []interface{}:
+		promoted := make([]interface{}, len(v))
+		for i := range v {
+			promoted[i] = promoteIntegers(v[i])
+		}
+		return promoted
+	default:
+		return value
+	}
+}
+
+func encodeValue(value interface{}) (savedValue, error) {
+	switch v := value.(type) {
+	case string:
+		return savedValue{Kind: stringKind, String: v}, nil
+	case int:
+		return savedValue{Kind: intKind, Int: v}, nil
+	case float64:
+		return savedValue{Kind: floatKind, Float: v}, nil
+	case [2]interface{}:
+		return encodeValues(rangeKind, v[:])
+	case []interface{}:
+		return encodeValues(listKind, v)
+	case Categories:
+		saved := savedValue{Kind: categoriesKind}
+		for _, category := range v {
+			saved.Values = append(saved.Values, savedValue{Kind: stringKind, String: category})
+		}
+		return saved, nil
+	default:
+		return savedValue{}, fmt.Errorf("Unsupported node value type %T", value)
+	}
+}
+
+func encodeValues(kind valueKind, values []interface{}) (savedValue, error) {
+	saved := savedValue{Kind: kind}
+	for _, value := range values {
+		encoded, err := encodeValue(value)
+		if err != nil {
+			return savedValue{}, err
+		}
+		saved.Values = append(saved.Values, encoded)
+	}
+	return saved, nil
+}
+
+func decodeValue(saved savedValue) (interface{}, error) {
+	switch saved.Kind {
+	case stringKind:
+		return saved.String, nil
+	case intKind:
+		return saved.Int, nil
+	case floatKind:
+		return saved.Float, nil
+	case rangeKind:
+		if len(saved.Values) != 2 {
+			return nil, fmt.Errorf("%w: range with %d values", ErrInvalidModel, len(saved.Values))
+		}
+		values, err := decodeValues(saved.Values)
+		if err != nil {
+			return nil, err
+		}
+		return [2]interface{}{values[0], values[1]}, nil
+	case listKind:
+		return decodeValues(saved.Values)
+	case categoriesKind:
+		categories := make(Categories, 0, len(saved.Values))
+		for _, value := range saved.Values {
+			if value.Kind != stringKind {
+				return nil, fmt.Errorf("%w: category of kind %d", ErrInvalidModel, value.Kind)
+			}
+			categories = append(categories, value.String)
+		}
+		return categories, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown value kind %d", ErrInvalidModel, saved.Kind)
+	}
+}
+
+func decodeValues(saved []savedValue) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(saved))
+	for _, value := range saved {
+		decoded, err := decodeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, decoded)
+	}
+	return values, nil
+}
@@ -0,0 +1,51 @@
+package gasonn
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		asonn, err := Train(trainX, trainY, WithStrategy(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buffer bytes.Buffer
+		if err := asonn.Save(&buffer); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(&buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(loaded.Nodes) != len(asonn.Nodes) {
+			t.Fatalf("Loaded %d nodes instead of %d", len(loaded.Nodes), len(asonn.Nodes))
+		}
+		for i := range asonn.Nodes {
+			if loaded.Nodes[i].Type != asonn.Nodes[i].Type || loaded.Nodes[i].Value != asonn.Nodes[i].Value {
+				t.Errorf("Node %d differs after reload", i)
+			}
+		}
+		for _, row := range trainX[1:] {
+			state, winner := asonn.activateCombinations(row, trainX[0])
+			loadedState, loadedWinner := loaded.activateCombinations(row, trainX[0])
+			if getClassOfObject(winner) != getClassOfObject(loadedWinner) {
+				t.Errorf("Different class after reload")
+			}
+			for i := range asonn.Nodes {
+				if math.Float64bits(state[asonn.Nodes[i]]) != math.Float64bits(loadedState[loaded.Nodes[i]]) {
+					t.Errorf("Different activation of node %d after reload", i)
+				}
+			}
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	if _, err := Load(bytes.NewBufferString("not a model")); !errors.Is(err, ErrInvalidModel) {
+		t.Errorf("Got error %v instead of %v", err, ErrInvalidModel)
+	}
+}
@@ -0,0 +1,324 @@
+package gasonn
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+type CalibrationMethod int
+
+const (
+	// SoftmaxCalibration applies a softmax with a fitted temperature to class scores.
+	SoftmaxCalibration CalibrationMethod = iota
+	// PlattCalibration fits a one-vs-rest sigmoid per class.
+	PlattCalibration
+	// IsotonicCalibration fits a one-vs-rest monotone step function per class.
+	IsotonicCalibration
+)
+
+// Calibration turns per-class scores into probabilities. A model without
+// calibration uses a softmax with temperature 1.
+type Calibration struct {
+	Method      CalibrationMethod
+	Temperature float64
+	Platt       map[string]PlattParameters
+	Isotonic    map[string]IsotonicCurve
+}
+
+// PlattParameters of the sigmoid 1/(1+exp(A*score+B)).
+type PlattParameters struct {
+	A float64
+	B float64
+}
+
+// IsotonicCurve is linearly interpolated between its points and constant outside them.
+type IsotonicCurve struct {
+	Scores        []float64
+	Probabilities []float64
+}
+
+// PredictProba returns a probability distribution over classes for every row of test.
+func (asonn *Asonn) PredictProba(test [][]string) ([]map[string]float64, error) {
+	predictions, err := asonn.Classify(test)
+	if err != nil {
+		return nil, err
+	}
+	calibration := asonn.Calibration
+	if calibration == nil {
+		calibration = &Calibration{Method: SoftmaxCalibration, Temperature: 1}
+	}
+	probabilities := make([]map[string]float64, 0, len(predictions))
+	for _, prediction := range predictions {
+		probabilities = append(probabilities, calibration.probabilities(prediction.Scores))
+	}
+	return probabilities, nil
+}
+
+// Calibrate fits calibration parameters on held-out rows x labelled with y,
+// using the same header conventions as Train, and stores them in the model.
+func (asonn *Asonn) Calibrate(x [][]string, y []string, method CalibrationMethod) error {
+	if asonn.IsRegression() {
+		return ErrRegression
+	}
+	if err := validate(x, y); err != nil {
+		return err
+	}
+	predictions, err := asonn.Classify(x)
+	if err != nil {
+		return err
+	}
+	var scores []map[string]float64
+	var labels []string
+	for i, prediction := range predictions {
+		if y[i+1] == "" {
+			continue
+		}
+		scores = append(scores, prediction.Scores)
+		labels = append(labels, y[i+1])
+	}
+	calibration := &Calibration{Method: method, Temperature: 1}
+	switch method {
+	case SoftmaxCalibration:
+		calibration.Temperature = fitTemperature(scores, labels)
+	case PlattCalibration:
+		calibration.Platt = make(map[string]PlattParameters)
+		for class, classScores := range oneVsRest(scores, labels) {
+			calibration.Platt[class] = fitPlatt(classScores.scores, classScores.positives)
+		}
+	case IsotonicCalibration:
+		calibration.Isotonic = make(map[string]IsotonicCurve)
+		for class, classScores := range oneVsRest(scores, labels) {
+			calibration.Isotonic[class] = fitIsotonic(classScores.scores, classScores.positives)
+		}
+	default:
+		return fmt.Errorf("Unknown calibration method %d", method)
+	}
+	asonn.Calibration = calibration
+	return nil
+}
+
+func (calibration *Calibration) probabilities(scores map[string]float64) map[string]float64 {
+	probabilities := make(map[string]float64, len(scores))
+	switch calibration.Method {
+	case PlattCalibration:
+		for class, score := range scores {
+			parameters := calibration.Platt[class]
+			probabilities[class] = 1 / (1 + math.Exp(parameters.A*score+parameters.B))
+		}
+	case IsotonicCalibration:
+		for class, score := range scores {
+			probabilities[class] = calibration.Isotonic[class].apply(score)
+		}
+	default:
+		return softmax(scores, calibration.Temperature)
+	}
+	sum := 0.0
+	for _, probability := range probabilities {
+		sum += probability
+	}
+	for class := range probabilities {
+		if sum > 0 {
+			probabilities[class] /= sum
+		} else {
+			probabilities[class] = 1 / float64(len(probabilities))
+		}
+	}
+	return probabilities
+}
+
+func softmax(scores map[string]float64, temperature float64) map[string]float64 {
+	if temperature <= 0 {
+		temperature = 1
+	}
+	maxScore := math.Inf(-1)
+	for _, score := range scores {
+		maxScore = math.Max(maxScore, score)
+	}
+	probabilities := make(map[string]float64, len(scores))
+	sum := 0.0
+	for class, score := range scores {
+		probabilities[class] = math.Exp((score - maxScore) / temperature)
+		sum += probabilities[class]
+	}
+	for class := range probabilities {
+		probabilities[class] /= sum
+	}
+	return probabilities
+}
+
+// fitTemperature minimizes the negative log-likelihood of labels with a
+// golden-section search over log(temperature).
+func fitTemperature(scores []map[string]float64, labels []string) float64 {
+	logLoss := func(logTemperature float64) float64 {
+		loss := 0.0
+		for i := range scores {
+			probability := softmax(scores[i], math.Exp(logTemperature))[labels[i]]
+			loss -= math.Log(math.Max(probability, 1e-15))
+		}
+		return loss
+	}
+	low, high := math.Log(1e-3), math.Log(1e3)
+	ratio := (math.Sqrt(5) - 1) / 2
+	for high-low > 1e-6 {
+		first := high - ratio*(high-low)
+		second := low + ratio*(high-low)
+		if logLoss(first) < logLoss(second) {
+			high = second
+		} else {
+			low = first
+		}
+	}
+	return math.Exp((low + high) / 2)
+}
+
+type binaryScores struct {
+	scores    []float64
+	positives []bool
+}
+
+func oneVsRest(scores []map[string]float64, labels []string) map[string]*binaryScores {
+	classes := make(map[string]*binaryScores)
+	for i := range scores {
+		for class, score := range scores[i] {
+			if classes[class] == nil {
+				classes[class] = &binaryScores{}
+			}
+			classes[class].scores = append(classes[class].scores, score)
+			classes[class].positives = append(classes[class].positives, labels[i] == class)
+		}
+	}
+	return classes
+}
+
+// fitPlatt fits a sigmoid with Newton's method and backtracking line search,
+// following Lin, Lin and Weng's note on Platt's probabilistic outputs.
+func fitPlatt(scores []float64, positives []bool) PlattParameters {
+	prior1, prior0 := 0.0, 0.0
+	for _, positive := range positives {
+		if positive {
+			prior1++
+		} else {
+			prior0++
+		}
+	}
+	hiTarget := (prior1 + 1) / (prior1 + 2)
+	loTarget := 1 / (prior0 + 2)
+	targets := make([]float64, len(scores))
+	for i, positive := range positives {
+		if positive {
+			targets[i] = hiTarget
+		} else {
+			targets[i] = loTarget
+		}
+	}
+	objective := func(a, b float64) float64 {
+		value := 0.0
+		for i, score := range scores {
+			fApB := score*a + b
+			if fApB >= 0 {
+				value += targets[i]*fApB + math.Log1p(math.Exp(-fApB))
+			} else {
+				value += (targets[i]-1)*fApB + math.Log1p(math.Exp(fApB))
+			}
+		}
+		return value
+	}
+	a, b := 0.0, math.Log((prior0+1)/(prior1+1))
+	value := objective(a, b)
+	for iteration := 0; iteration < 100; iteration++ {
+		h11, h22, h21, g1, g2 := 1e-12, 1e-12, 0.0, 0.0, 0.0
+		for i, score := range scores {
+			fApB := score*a + b
+			var p, q float64
+			if fApB >= 0 {
+				p = math.Exp(-fApB) / (1 + math.Exp(-fApB))
+				q = 1 / (1 + math.Exp(-fApB))
+			} else {
+				p = 1 / (1 + math.Exp(fApB))
+				q = math.Exp(fApB) / (1 + math.Exp(fApB))
+			}
+			d2 := p * q
+			h11 += score * score * d2
+			h22 += d2
+			h21 += score * d2
+			d1 := targets[i] - p
+			g1 += score * d1
+			g2 += d1
+		}
+		if math.Abs(g1) < 1e-5 && math.Abs(g2) < 1e-5 {
+			break
+		}
+		det := h11*h22 - h21*h21
+		dA := -(h22*g1 - h21*g2) / det
+		dB := -(-h21*g1 + h11*g2) / det
+		gd := g1*dA + g2*dB
+		step := 1.0
+		for ; step >= 1e-10; step /= 2 {
+			newA, newB := a+step*dA, b+step*dB
+			if newValue := objective(newA, newB); newValue < value+1e-4*step*gd {
+				a, b, value = newA, newB, newValue
+				break
+			}
+		}
+		if step < 1e-10 {
+			break
+		}
+	}
+	return PlattParameters{A: a, B: b}
+}
+
+// fitIsotonic fits a non-decreasing step function with the pool adjacent violators algorithm.
+func fitIsotonic(scores []float64, positives []bool) IsotonicCurve {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] < scores[order[j]]
+	})
+	type block struct {
+		scoreSum float64
+		sum      float64
+		count    float64
+	}
+	var blocks []block
+	for _, i := range order {
+		value := 0.0
+		if positives[i] {
+			value = 1
+		}
+		blocks = append(blocks, block{scoreSum: scores[i], sum: value, count: 1})
+		for len(blocks) > 1 {
+			last, previous := blocks[len(blocks)-1], blocks[len(blocks)-2]
+			if previous.sum/previous.count < last.sum/last.count {
+				break
+			}
+			blocks = blocks[:len(blocks)-1]
+			blocks[len(blocks)-1] = block{scoreSum: previous.scoreSum + last.scoreSum, sum: previous.sum + last.sum, count: previous.count + last.count}
+		}
+	}
+	var curve IsotonicCurve
+	for _, b := range blocks {
+		curve.Scores = append(curve.Scores, b.scoreSum/b.count)
+		curve.Probabilities = append(curve.Probabilities, b.sum/b.count)
+	}
+	return curve
+}
+
+func (curve IsotonicCurve) apply(score float64) float64 {
+	n := len(curve.Scores)
+	if n == 0 {
+		return 0
+	}
+	if score <= curve.Scores[0] {
+		return curve.Probabilities[0]
+	}
+	if score >= curve.Scores[n-1] {
+		return curve.Probabilities[n-1]
+	}
+	i := sort.SearchFloat64s(curve.Scores, score)
+	low, high := curve.Scores[i-1], curve.Scores[i]
+	share := (score - low) / (high - low)
+	return curve.Probabilities[i-1] + share*(curve.Probabilities[i]-curve.Probabilities[i-1])
+}
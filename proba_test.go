@@ -0,0 +1,58 @@
+package gasonn
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestPredictProba(t *testing.T) {
+	for _, method := range []CalibrationMethod{SoftmaxCalibration, PlattCalibration, IsotonicCalibration} {
+		asonn, err := Train(trainX, trainY)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := asonn.Calibrate(overlappingX, overlappingY, method); err != nil {
+			t.Fatal(err)
+		}
+		var buffer bytes.Buffer
+		if err := asonn.Save(&buffer); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(&buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		probabilities, err := loaded.PredictProba(trainX)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, distribution := range probabilities {
+			sum := 0.0
+			for _, probability := range distribution {
+				if probability < 0 || probability > 1 {
+					t.Errorf("Method %d: invalid probability %f", method, probability)
+				}
+				sum += probability
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("Method %d: probabilities sum to %f", method, sum)
+			}
+			if distribution[trainY[i+1]] < 0.5 {
+				t.Errorf("Method %d: probability of true class is %f", method, distribution[trainY[i+1]])
+			}
+		}
+	}
+}
+
+func TestFitIsotonic(t *testing.T) {
+	curve := fitIsotonic([]float64{1, 2, 3, 4}, []bool{false, true, false, true})
+	for i := 1; i < len(curve.Probabilities); i++ {
+		if curve.Probabilities[i] < curve.Probabilities[i-1] {
+			t.Errorf("Curve is not monotone: %v", curve.Probabilities)
+		}
+	}
+	if curve.apply(0) != 0 || curve.apply(5) != 1 {
+		t.Errorf("Invalid values outside of the curve")
+	}
+}
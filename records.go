@@ -0,0 +1,296 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrNotStruct     = errors.New("Not a struct")
+	ErrUnknownField  = errors.New("Unknown field")
+	ErrFieldConflict = errors.New("Field name given twice")
+)
+
+// PredictOne classifies a single record mapping feature names to values.
+// Values are formatted as cells, with nil being a missing value. Keys the
+// model has no feature for are passed to Warn.
+func (asonn *Asonn) PredictOne(record map[string]interface{}) (Prediction, error) {
+	header := asonn.Header()
+	row := make([]string, len(header))
+	var missing []string
+	for j, name := range header {
+		value, ok := record[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		row[j] = formatCell(value)
+	}
+	if len(record) > len(header)-len(missing) {
+		features := newSet(header)
+		var unknown []string
+		for name := range record {
+			if !features[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		asonn.warn(&UnknownColumnError{Columns: unknown})
+	}
+	if len(missing) > 0 {
+		return Prediction{}, &MissingColumnError{Columns: missing}
+	}
+	return asonn.predictRow(header, row)
+}
+
+// PredictStruct classifies a struct or a pointer to one. Fields are read
+// like TrainStructs reads them and fields the model has no feature for are
+// ignored.
+func (asonn *Asonn) PredictStruct(v interface{}) (Prediction, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return Prediction{}, fmt.Errorf("%w: %T", ErrNotStruct, v)
+	}
+	fields, err := cachedStructFields(value.Type())
+	if err != nil {
+		return Prediction{}, err
+	}
+	header := asonn.Header()
+	row := make([]string, len(header))
+	var missing []string
+	for j, name := range header {
+		field, ok := fields.byName[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		row[j] = formatCell(field.read(value))
+	}
+	if len(missing) > 0 {
+		return Prediction{}, &MissingColumnError{Columns: missing}
+	}
+	return asonn.predictRow(header, row)
+}
+
+// predictRow classifies a single row whose cells follow header, the Header of
+// the model, activating combinations directly without a prediction table.
+func (asonn *Asonn) predictRow(header []string, row []string) (Prediction, error) {
+	classes, err := asonn.classes()
+	if err != nil {
+		return Prediction{}, err
+	}
+	for _, cell := range row {
+		if isMissing(cell) {
+			row = asonn.fillMissing([][]string{header, row})[1]
+			break
+		}
+	}
+	graph := asonn.graph()
+	inputs := make([]float64, len(row))
+	for j, cell := range row {
+		if asonn.Schema == nil {
+			inputs[j] = activationInput(convertToCorrectType(cell))
+			continue
+		}
+		column := asonn.Schema[j]
+		if column.skips(cell) || column.Type == CategoricalColumn {
+			continue
+		}
+		value, err := column.parse(cell)
+		if err != nil {
+			return Prediction{}, err
+		}
+		inputs[j] = activationInput(value)
+	}
+	state, winner := asonn.activateInputs(header, func(j int, feature *Node) (string, float64, bool) {
+		if column, ok := graph.columns[feature]; ok && column.skips(row[j]) {
+			return "", 0, false
+		}
+		return row[j], inputs[j], true
+	})
+	return asonn.prediction(state, winner, classes), nil
+}
+
+// TrainStructs trains a model on items, whose exported fields are features
+// named by their `gasonn:"name"` tag or their field name. Fields tagged
+// `gasonn:"-"` are skipped. labelField names the label field by tag or field
+// name. Fields of untagged embedded structs are features like the fields of
+// the item itself, missing behind nil pointers, and two features of the same
+// name are rejected with ErrFieldConflict. Float fields are numeric columns,
+// integer fields integer columns and others categorical, unless opts hold
+// WithSchema.
+func TrainStructs[T any](items []T, labelField string, opts ...Option) (*Asonn, error) {
+	itemType := reflect.TypeOf((*T)(nil)).Elem()
+	for itemType.Kind() == reflect.Pointer {
+		itemType = itemType.Elem()
+	}
+	if itemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %v", ErrNotStruct, itemType)
+	}
+	fields, err := cachedStructFields(itemType)
+	if err != nil {
+		return nil, err
+	}
+	var features []structField
+	var label *structField
+	for _, field := range fields.list {
+		if field.name == labelField || field.goName == labelField {
+			field := field
+			label = &field
+			continue
+		}
+		features = append(features, field)
+	}
+	if label == nil {
+		return nil, fmt.Errorf("%w: %s in %v", ErrUnknownField, labelField, itemType)
+	}
+	header := make([]string, len(features))
+	var schema Schema
+	for j, field := range features {
+		header[j] = field.name
+		schema = append(schema, Column{Name: field.name, Type: field.columnType})
+	}
+	x, y := [][]string{header}, []string{label.name}
+	for _, item := range items {
+		value := reflect.ValueOf(item)
+		for value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%w: nil item", ErrNotStruct)
+		}
+		row := make([]string, len(features))
+		for j, field := range features {
+			row[j] = formatCell(field.read(value))
+		}
+		x = append(x, row)
+		y = append(y, formatCell(label.read(value)))
+	}
+	return Train(x, y, append([]Option{WithSchema(schema)}, opts...)...)
+}
+
+type structField struct {
+	name       string
+	goName     string
+	index      []int
+	columnType ColumnType
+}
+
+// read returns the field of the struct value, or nil when it sits in an
+// embedded struct behind a nil pointer.
+func (field structField) read(value reflect.Value) interface{} {
+	for i, index := range field.index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return nil
+			}
+			value = value.Elem()
+		}
+		value = value.Field(index)
+	}
+	return value.Interface()
+}
+
+type structFieldSet struct {
+	list   []structField
+	byName map[string]structField
+	err    error
+}
+
+// fieldCache holds the structFieldSet of every struct type read so far.
+var fieldCache sync.Map
+
+func cachedStructFields(structType reflect.Type) (structFieldSet, error) {
+	if cached, ok := fieldCache.Load(structType); ok {
+		return cached.(structFieldSet), cached.(structFieldSet).err
+	}
+	fields := structFieldSet{list: structFields(structType, nil), byName: make(map[string]structField)}
+	for _, field := range fields.list {
+		if _, ok := fields.byName[field.name]; ok {
+			fields.err = fmt.Errorf("%w: %s in %v", ErrFieldConflict, field.name, structType)
+			break
+		}
+		fields.byName[field.name] = field
+	}
+	fieldCache.Store(structType, fields)
+	return fields, fields.err
+}
+
+// structFields lists the features of structType. Untagged embedded structs
+// and pointers to them are flattened like encoding/json does, so their
+// fields are features of the outer struct, while tagged ones are single
+// fields.
+func structFields(structType reflect.Type, parent []int) []structField {
+	var fields []structField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("gasonn")
+		if tag == "-" {
+			continue
+		}
+		index := append(append([]int(nil), parent...), i)
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && tag == "" && embedded.Kind() == reflect.Struct {
+			fields = append(fields, structFields(embedded, index)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, structField{name: name, goName: field.Name, index: index, columnType: columnTypeOf(field.Type)})
+	}
+	return fields
+}
+
+func columnTypeOf(fieldType reflect.Type) ColumnType {
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	switch fieldType.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return NumericColumn
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return IntegerColumn
+	default:
+		return CategoricalColumn
+	}
+}
+
+// formatCell writes a record value as a cell, nil and nil pointers as a
+// missing value.
+func formatCell(value interface{}) string {
+	reflected := reflect.ValueOf(value)
+	for reflected.Kind() == reflect.Pointer {
+		if reflected.IsNil() {
+			return ""
+		}
+		reflected = reflected.Elem()
+	}
+	switch reflected.Kind() {
+	case reflect.Invalid:
+		return ""
+	case reflect.Float32:
+		return strconv.FormatFloat(reflected.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return formatSampleValue(reflected.Float())
+	case reflect.String:
+		return reflected.String()
+	default:
+		return fmt.Sprint(reflected.Interface())
+	}
+}
@@ -0,0 +1,192 @@
+package gasonn
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type flower struct {
+	ID     int     `gasonn:"-"`
+	Length float64 `gasonn:"a"`
+	Width  float64 `gasonn:"b"`
+	Colour *string `gasonn:"colour"`
+	Class  string
+}
+
+func flowers(t *testing.T) []flower {
+	var items []flower
+	for i, row := range trainX[1:] {
+		colour := "red"
+		if trainY[i+1] == "n" {
+			colour = "blue"
+		}
+		length, err := strconv.ParseFloat(row[0], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		width, err := strconv.ParseFloat(row[1], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, flower{ID: i, Length: length, Width: width, Colour: &colour, Class: trainY[i+1]})
+	}
+	return items
+}
+
+func TestRecords(t *testing.T) {
+	items := flowers(t)
+	asonn, err := TrainStructs(items, "Class", WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Schema{{Name: "a", Type: NumericColumn}, {Name: "b", Type: NumericColumn}, {Name: "colour", Type: CategoricalColumn}}
+	if len(asonn.Schema) != len(expected) {
+		t.Fatalf("Schema %v instead of %v", asonn.Schema, expected)
+	}
+	for j := range expected {
+		if asonn.Schema[j].Name != expected[j].Name || asonn.Schema[j].Type != expected[j].Type {
+			t.Errorf("Column %v instead of %v", asonn.Schema[j], expected[j])
+		}
+	}
+	for _, item := range items {
+		prediction, err := asonn.PredictStruct(&item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prediction.Label != item.Class {
+			t.Errorf("%v classified as %s", item, prediction.Label)
+		}
+	}
+	prediction, err := asonn.PredictOne(map[string]interface{}{"a": 1, "b": float32(2.6), "colour": "red"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prediction.Label != "p" {
+		t.Errorf("Record classified as %s instead of p", prediction.Label)
+	}
+	if _, err := asonn.PredictOne(map[string]interface{}{"a": 1.1}); !errors.Is(err, ErrMissingColumn) {
+		t.Errorf("Got error %v instead of %v", err, ErrMissingColumn)
+	}
+	missing, err := asonn.PredictStruct(flower{Length: 3.2, Width: 0.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing.Label != "n" {
+		t.Errorf("Record with a nil field classified as %s instead of n", missing.Label)
+	}
+}
+
+type measurements struct {
+	Length float64 `gasonn:"a"`
+	Width  float64 `gasonn:"b"`
+}
+
+type labelled struct {
+	Class string
+}
+
+type embeddedFlower struct {
+	*measurements
+	labelled
+	Colour string `gasonn:"colour"`
+}
+
+type conflictingFlower struct {
+	measurements
+	Length float64 `gasonn:"a"`
+}
+
+func TestEmbeddedRecords(t *testing.T) {
+	var items []embeddedFlower
+	for _, item := range flowers(t) {
+		items = append(items, embeddedFlower{&measurements{item.Length, item.Width}, labelled{item.Class}, *item.Colour})
+	}
+	asonn, err := TrainStructs(items, "Class")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header := asonn.Header(); !reflect.DeepEqual(header, []string{"a", "b", "colour"}) {
+		t.Errorf("Header %v instead of [a b colour]", header)
+	}
+	for _, item := range items {
+		prediction, err := asonn.PredictStruct(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prediction.Label != item.Class {
+			t.Errorf("%v classified as %s", item, prediction.Label)
+		}
+	}
+	missing, err := asonn.PredictStruct(embeddedFlower{Colour: "blue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing.Label != "n" {
+		t.Errorf("Record with a nil embedded struct classified as %s instead of n", missing.Label)
+	}
+	if _, err := TrainStructs([]conflictingFlower{}, "a"); !errors.Is(err, ErrFieldConflict) {
+		t.Errorf("Got error %v instead of %v", err, ErrFieldConflict)
+	}
+}
+
+func TestPredictOneMatchesClassify(t *testing.T) {
+	asonn, err := Train(overlappingX, overlappingY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := asonn.Classify(overlappingX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range overlappingX[1:] {
+		prediction, err := asonn.PredictOne(map[string]interface{}{"a": row[0], "b": row[1]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(prediction, expected[i]) {
+			t.Errorf("Row %d predicted as %+v instead of %+v", i+1, prediction, expected[i])
+		}
+	}
+}
+
+type formatCellTestData struct {
+	value interface{}
+	cell  string
+}
+
+var formatCellTests = []formatCellTestData{
+	{nil, ""},
+	{(*int)(nil), ""},
+	{3, "3"},
+	{uint8(7), "7"},
+	{2.0, "2.0"},
+	{float32(0.1), "0.1"},
+	{true, "true"},
+	{"red", "red"},
+}
+
+func TestFormatCell(t *testing.T) {
+	for _, testData := range formatCellTests {
+		if cell := formatCell(testData.value); cell != testData.cell {
+			t.Errorf("%#v formatted as %q instead of %q", testData.value, cell, testData.cell)
+		}
+	}
+}
+
+func TestRecordErrors(t *testing.T) {
+	if _, err := TrainStructs([]flower{}, "Label"); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Got error %v instead of %v", err, ErrUnknownField)
+	}
+	if _, err := TrainStructs([]int{1}, "Class"); !errors.Is(err, ErrNotStruct) {
+		t.Errorf("Got error %v instead of %v", err, ErrNotStruct)
+	}
+	asonn, err := Train(trainX, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := asonn.PredictStruct((*flower)(nil)); !errors.Is(err, ErrNotStruct) {
+		t.Errorf("Got error %v instead of %v", err, ErrNotStruct)
+	}
+}
@@ -0,0 +1,256 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+var (
+	ErrRegression    = errors.New("Not supported by regression models")
+	ErrNotRegression = errors.New("Not a regression model")
+)
+
+// Estimate is the regression output of a single row.
+type Estimate struct {
+	// Value averages the target means of the intervals whose strongest
+	// combination reaches Config.EstimateShare of the winner's activation,
+	// weighted by that activation.
+	Value float64
+	// Low and High bound the target interval of the winning combination.
+	Low         float64
+	High        float64
+	Combination *Node
+}
+
+// WithTargetIntervals sets the number of target intervals TrainRegression
+// builds combinations for. By default it follows Sturges' rule.
+func WithTargetIntervals(k int) Option {
+	return func(o *options) {
+		o.intervals = k
+	}
+}
+
+// TrainRegression builds an Asonn predicting the continuous target y, whose
+// first element is the target name, from x, whose first row holds feature
+// names. Target values are Value nodes of a Target node linked by ASIM
+// weights like values of a numeric feature. They are split into intervals of
+// about the same number of rows at the weakest links, and the intervals become
+// the classes combinations are built for. Rows with an empty target are
+// skipped. Regress estimates targets, and Classify labels rows with the target
+// interval of the winning combination. Regression models cannot learn, forget,
+// merge or calibrate.
+func TrainRegression(x [][]string, y []string, opts ...Option) (*Asonn, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if o.intervals < 0 {
+		return nil, fmt.Errorf("Invalid number of target intervals %d", o.intervals)
+	}
+	if err := validate(x, y); err != nil {
+		return nil, err
+	}
+	var targets []float64
+	for i := 1; i < len(y); i++ {
+		if y[i] == "" {
+			continue
+		}
+		target, err := strconv.ParseFloat(y[i], 64)
+		if err != nil || math.IsNaN(target) || math.IsInf(target, 0) {
+			return nil, fmt.Errorf("%w: target %q in row %d", ErrInvalidValue, y[i], i)
+		}
+		targets = append(targets, target)
+	}
+	if o.intervals == 0 {
+		o.intervals = int(math.Ceil(math.Log2(float64(len(targets))))) + 1
+	}
+	intervals := targetIntervals(targets, o.intervals)
+	schema, err := trainingSchema(x, y, o.schema, o.missing)
+	if err != nil {
+		return nil, err
+	}
+	x = schema.fillMissing(x, x)
+	labels := make([]interface{}, len(x)-1)
+	next := 0
+	for i := 1; i < len(y); i++ {
+		if y[i] != "" {
+			labels[i-1] = intervals[intervalOf(intervals, targets[next])]
+			next++
+		}
+	}
+	asonn := &Asonn{Config: &o.config, Schema: schema}
+	classNodes, err := asonn.addLabelledObjects(x, labels)
+	if err != nil {
+		return nil, err
+	}
+	asonn.addTarget(y[0], targets, intervals, classNodes)
+	if err := asonn.build(o.strategy, classNodes); err != nil {
+		return nil, err
+	}
+	return asonn, nil
+}
+
+// targetIntervals splits targets into at most k intervals of about the same
+// number of targets. Every cut between intervals lies at the weakest ASIM
+// link, the largest gap between neighbouring sorted targets, within half an
+// interval of its quantile, and equal targets are never split.
+func targetIntervals(targets []float64, k int) [][2]interface{} {
+	sorted := append([]float64(nil), targets...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	gap := func(c int) float64 {
+		return sorted[c] - sorted[c-1]
+	}
+	var intervals [][2]interface{}
+	start := 0
+	for b := 1; b < k; b++ {
+		center := b * n / k
+		cut := -1
+		for c := maxInt(start+1, (2*b-1)*n/(2*k)); c <= minInt(n-1, (2*b+1)*n/(2*k)); c++ {
+			if gap(c) == 0 {
+				continue
+			}
+			if cut < 0 || gap(c) > gap(cut) || gap(c) == gap(cut) && math.Abs(float64(c-center)) < math.Abs(float64(cut-center)) {
+				cut = c
+			}
+		}
+		if cut < 0 {
+			continue
+		}
+		intervals = append(intervals, [2]interface{}{sorted[start], sorted[cut-1]})
+		start = cut
+	}
+	return append(intervals, [2]interface{}{sorted[start], sorted[n-1]})
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func intervalOf(intervals [][2]interface{}, target float64) int {
+	return sort.Search(len(intervals)-1, func(i int) bool {
+		return intervals[i][1].(float64) >= target
+	})
+}
+
+// addTarget adds a Target node named name with a Value node for every distinct
+// target, sorted and linked by ASIM weights. A value is connected to the Class
+// node of its interval with the share of the interval's targets equal to it.
+func (asonn *Asonn) addTarget(name string, targets []float64, intervals [][2]interface{}, classNodes []*Node) {
+	counts := make(map[float64]int)
+	sizes := make([]int, len(intervals))
+	for _, target := range targets {
+		counts[target]++
+		sizes[intervalOf(intervals, target)]++
+	}
+	classes := make(map[[2]interface{}]*Node)
+	for _, classNode := range classNodes {
+		classes[classNode.Value.([2]interface{})] = classNode
+	}
+	distinct := make([]float64, 0, len(counts))
+	for target := range counts {
+		distinct = append(distinct, target)
+	}
+	sort.Float64s(distinct)
+	targetNode := NewNode(name, Target)
+	values := make([]*Node, len(distinct))
+	for i, target := range distinct {
+		valueNode := NewNode(target, Value)
+		values[i] = &valueNode
+		addConnection(&targetNode, &valueNode, 1)
+		interval := intervalOf(intervals, target)
+		addConnection(&valueNode, classes[intervals[interval]], float64(counts[target])/float64(sizes[interval]))
+	}
+	linkValues(values)
+	asonn.addNodes(append([]*Node{&targetNode}, values...)...)
+}
+
+func isTargetValue(node *Node) bool {
+	if node.Type != Value {
+		return false
+	}
+	for _, connection := range node.Connections {
+		if connection.Node.Type == Target {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRegression reports whether the model was trained by TrainRegression.
+func (asonn *Asonn) IsRegression() bool {
+	return len(asonn.graph().nodes(Target)) > 0
+}
+
+type targetInterval struct {
+	low  float64
+	high float64
+	mean float64
+}
+
+// targetBounds returns the target interval of every class of a regression
+// model by its label.
+func (asonn *Asonn) targetBounds() (map[string]targetInterval, error) {
+	if !asonn.IsRegression() {
+		return nil, ErrNotRegression
+	}
+	intervals := make(map[string]targetInterval)
+	for _, classNode := range asonn.graph().nodes(Class) {
+		bounds, ok := classNode.Value.([2]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: class %v is not a target interval", ErrInvalidModel, classNode.Value)
+		}
+		interval := targetInterval{low: activationInput(bounds[0]), high: activationInput(bounds[1])}
+		for _, connection := range classNode.Connections {
+			if isTargetValue(connection.Node) {
+				interval.mean += activationInput(connection.Node.Value) * connection.Weight
+			}
+		}
+		intervals[classLabel(classNode)] = interval
+	}
+	return intervals, nil
+}
+
+// Regress estimates the targets of rows of test, whose first row holds
+// feature names, with a model trained by TrainRegression. Columns are matched
+// like in Classify.
+func (asonn *Asonn) Regress(test [][]string) ([]Estimate, error) {
+	intervals, err := asonn.targetBounds()
+	if err != nil {
+		return nil, err
+	}
+	classes, err := asonn.checkRows(test)
+	if err != nil {
+		return nil, err
+	}
+	test = asonn.fillMissing(test)
+	share := asonn.config().EstimateShare
+	estimates := make([]Estimate, 0, len(test)-1)
+	for _, row := range test[1:] {
+		prediction := asonn.classifyRow(row, test[0], classes)
+		estimates = append(estimates, estimate(prediction, intervals, share))
+	}
+	return estimates, nil
+}
+
+func estimate(prediction Prediction, intervals map[string]targetInterval, share float64) Estimate {
+	winner := intervals[prediction.Label]
+	result := Estimate{Value: winner.mean, Low: winner.low, High: winner.high, Combination: prediction.Combination}
+	sum, weights := 0.0, 0.0
+	for _, score := range prediction.Ranking {
+		if score.Score > 0 && score.Score >= share*prediction.Ranking[0].Score {
+			sum += score.Score * intervals[score.Class].mean
+			weights += score.Score
+		}
+	}
+	if weights > 0 {
+		result.Value = sum / weights
+	}
+	return result
+}
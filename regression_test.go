@@ -0,0 +1,269 @@
+package gasonn
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/jakubkosno/gasonn/dataset"
+	"github.com/jakubkosno/gasonn/eval"
+)
+
+var regressionY = []string{"t", "1.0", "1.5", "7.0", "8.0", "1.2", "7.5"}
+
+type targetIntervalsTestData struct {
+	targets   []float64
+	k         int
+	intervals [][2]interface{}
+}
+
+var targetIntervalsTests = []targetIntervalsTestData{
+	{[]float64{3, 1, 2, 4}, 2, [][2]interface{}{{1.0, 2.0}, {3.0, 4.0}}},
+	{[]float64{1, 1, 1, 2}, 2, [][2]interface{}{{1.0, 1.0}, {2.0, 2.0}}},
+	{[]float64{1, 2, 2, 2, 3}, 2, [][2]interface{}{{1.0, 1.0}, {2.0, 3.0}}},
+	{[]float64{1.0, 1.2, 1.4, 7.0}, 2, [][2]interface{}{{1.0, 1.4}, {7.0, 7.0}}},
+	{[]float64{1, 2, 2, 2, 3}, 3, [][2]interface{}{{1.0, 1.0}, {2.0, 2.0}, {3.0, 3.0}}},
+	{[]float64{5, 6}, 4, [][2]interface{}{{5.0, 5.0}, {6.0, 6.0}}},
+	{[]float64{5}, 1, [][2]interface{}{{5.0, 5.0}}},
+}
+
+func TestTargetIntervals(t *testing.T) {
+	for _, testData := range targetIntervalsTests {
+		intervals := targetIntervals(testData.targets, testData.k)
+		if len(intervals) != len(testData.intervals) {
+			t.Errorf("%v split into %v instead of %v", testData.targets, intervals, testData.intervals)
+			continue
+		}
+		for i := range intervals {
+			if intervals[i] != testData.intervals[i] {
+				t.Errorf("%v split into %v instead of %v", testData.targets, intervals, testData.intervals)
+			}
+		}
+	}
+}
+
+func TestRegression(t *testing.T) {
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		asonn, err := TrainRegression(trainX, regressionY, WithStrategy(strategy), WithTargetIntervals(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !asonn.IsRegression() {
+			t.Fatal("Model is not a regression model")
+		}
+		targets := asonn.graph().nodes(Target)
+		if len(targets) != 1 || targets[0].Value != "t" || len(targets[0].Connections) != 6 {
+			t.Fatalf("Target nodes %v instead of t with 6 values", targets)
+		}
+		intervals, err := asonn.targetBounds()
+		if err != nil {
+			t.Fatal(err)
+		}
+		low := intervals["[1, 1.5]"]
+		if low.low != 1 || low.high != 1.5 || math.Abs(low.mean-3.7/3) > 1e-12 {
+			t.Errorf("Interval %+v instead of [1, 1.5] with mean %v", low, 3.7/3)
+		}
+		var buffer bytes.Buffer
+		if err := asonn.Save(&buffer); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(&buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		estimates, err := asonn.Regress(trainX)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loadedEstimates, err := loaded.Regress(trainX)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, estimate := range estimates {
+			if estimate.Value < 1 || estimate.Value > 8 || estimate.Low > estimate.High {
+				t.Errorf("Estimate %+v of row %d out of the target range", estimate, i+1)
+			}
+			if estimate.Value != loadedEstimates[i].Value || estimate.Low != loadedEstimates[i].Low {
+				t.Errorf("Estimate %+v of row %d is %+v after reload", estimate, i+1, loadedEstimates[i])
+			}
+			target := []float64{1.0, 1.5, 7.0, 8.0, 1.2, 7.5}[i]
+			if target < estimate.Low || target > estimate.High {
+				t.Errorf("Target %v of row %d outside [%v, %v]", target, i+1, estimate.Low, estimate.High)
+			}
+		}
+	}
+}
+
+func TestEstimateShare(t *testing.T) {
+	config := DefaultConfig()
+	config.EstimateShare = 1
+	asonn, err := TrainRegression(overlappingX, append(append([]string{}, regressionY...), "1.1"), WithStrategy(MultiLayer), WithConfig(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervals, err := asonn.targetBounds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimates, err := asonn.Regress(overlappingX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, estimate := range estimates {
+		for _, interval := range intervals {
+			if interval.low == estimate.Low && interval.high == estimate.High && estimate.Value != interval.mean {
+				t.Errorf("Estimate %v of row %d is not the winning interval mean %v", estimate.Value, i+1, interval.mean)
+			}
+		}
+	}
+}
+
+func TestRegressionErrors(t *testing.T) {
+	if _, err := TrainRegression(trainX, trainY); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Got error %v instead of %v", err, ErrInvalidValue)
+	}
+	if _, err := TrainRegression(trainX, regressionY, WithTargetIntervals(-1)); err == nil {
+		t.Error("Negative number of target intervals accepted")
+	}
+	classifier, err := Train(trainX, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := classifier.Regress(trainX); !errors.Is(err, ErrNotRegression) {
+		t.Errorf("Got error %v instead of %v", err, ErrNotRegression)
+	}
+	asonn, err := TrainRegression(trainX, regressionY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := asonn.Learn([]string{"1.0", "2.0"}, "1.1"); !errors.Is(err, ErrRegression) {
+		t.Errorf("Got error %v instead of %v", err, ErrRegression)
+	}
+	if err := asonn.Forget("O1"); !errors.Is(err, ErrRegression) {
+		t.Errorf("Got error %v instead of %v", err, ErrRegression)
+	}
+	if _, err := Merge(asonn, asonn); !errors.Is(err, ErrRegression) {
+		t.Errorf("Got error %v instead of %v", err, ErrRegression)
+	}
+}
+
+type regressionIrisTestData struct {
+	target string
+	mae    float64
+	rmse   float64
+	r2     float64
+}
+
+// Bounds of multi-layer models estimating a measurement of Fisher's iris
+// from the other three, a little above the errors and below the R2 measured.
+var regressionIrisTests = []regressionIrisTestData{
+	{"sepal-length", 0.40, 0.55, 0.60},
+	{"sepal-width", 0.30, 0.40, 0.30},
+	{"petal-width", 0.22, 0.30, 0.85},
+}
+
+func TestRegressionIris(t *testing.T) {
+	for _, testData := range regressionIrisTests {
+		t.Run(testData.target, func(t *testing.T) {
+			x, _ := loadFixture(t, "iris")
+			x, y, err := dataset.SplitTarget(x, testData.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report := evaluateHalves(t, x, y, MultiLayer)
+			if report.MAE > testData.mae || report.RMSE > testData.rmse || report.R2 < testData.r2 {
+				t.Errorf("MAE %.4f, RMSE %.4f, R2 %.4f instead of at most %v, %v and at least %v", report.MAE, report.RMSE, report.R2, testData.mae, testData.rmse, testData.r2)
+			}
+		})
+	}
+}
+
+type regressionMtcarsTestData struct {
+	strategy Strategy
+	mae      float64
+	rmse     float64
+	r2       float64
+}
+
+// Bounds of models estimating the mpg of mtcars from its other columns, a
+// little above the errors and below the R2 measured.
+var regressionMtcarsTests = []regressionMtcarsTestData{
+	{SingleLayer, 3.4, 3.9, 0.40},
+	{MultiLayer, 3.0, 3.5, 0.50},
+}
+
+func TestRegressionMtcars(t *testing.T) {
+	x, y := loadFixture(t, "mtcars")
+	for _, testData := range regressionMtcarsTests {
+		report := evaluateHalves(t, x, y, testData.strategy)
+		if report.MAE > testData.mae || report.RMSE > testData.rmse || report.R2 < testData.r2 {
+			t.Errorf("Strategy %d: MAE %.4f, RMSE %.4f, R2 %.4f instead of at most %v, %v and at least %v", testData.strategy, report.MAE, report.RMSE, report.R2, testData.mae, testData.rmse, testData.r2)
+		}
+	}
+	// Single-layer combinations hold their seeds, so every training target
+	// is within the interval estimated for its row.
+	asonn, err := TrainRegression(x, y, WithStrategy(SingleLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimates, err := asonn.Regress(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var yTrue, yPred, low, high []float64
+	for i, estimate := range estimates {
+		target, err := strconv.ParseFloat(y[i+1], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		yTrue, yPred = append(yTrue, target), append(yPred, estimate.Value)
+		low, high = append(low, estimate.Low), append(high, estimate.High)
+	}
+	report, err := eval.EvaluateIntervals(yTrue, yPred, low, high)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *report.Coverage != 1 {
+		t.Errorf("Coverage %.4f of the training targets", *report.Coverage)
+	}
+}
+
+// evaluateHalves trains a regression model on even rows and evaluates it on
+// odd rows.
+func evaluateHalves(t *testing.T, x [][]string, y []string, strategy Strategy) *eval.RegressionReport {
+	t.Helper()
+	xTrain, yTrain := [][]string{x[0]}, []string{y[0]}
+	xTest := [][]string{x[0]}
+	var yTest []float64
+	for i := 1; i < len(x); i++ {
+		if i%2 == 0 {
+			xTrain, yTrain = append(xTrain, x[i]), append(yTrain, y[i])
+			continue
+		}
+		target, err := strconv.ParseFloat(y[i], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xTest, yTest = append(xTest, x[i]), append(yTest, target)
+	}
+	asonn, err := TrainRegression(xTrain, yTrain, WithStrategy(strategy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimates, err := asonn.Regress(xTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var yPred []float64
+	for _, estimate := range estimates {
+		yPred = append(yPred, estimate.Value)
+	}
+	report, err := eval.EvaluateRegression(yTest, yPred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("MAE %.4f, RMSE %.4f, R2 %.4f", report.MAE, report.RMSE, report.R2)
+	return report
+}
@@ -0,0 +1,206 @@
+package gasonn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Rule is the IF-THEN reading of a Combination node.
+type Rule struct {
+	ID         int
+	Class      string
+	Conditions []Condition
+	Seeds      int
+	Weeds      int
+	// Parent is the ID of the rule refined by this one in a multi-layer model, -1 otherwise.
+	Parent int
+}
+
+// Condition is a Range node of a rule with its range-to-combination weight.
+// Conditions on categorical features hold Categories instead of Min and Max,
+// and those on ordinal features hold both.
+type Condition struct {
+	Feature    string
+	Min        float64
+	Max        float64
+	Categories []string
+	Weight     float64
+}
+
+type RuleFormat int
+
+const (
+	RulesText RuleFormat = iota
+	RulesMarkdown
+	RulesJSON
+)
+
+// ExtractRules returns one rule per Combination node in the order of asonn.Nodes.
+func (asonn *Asonn) ExtractRules() ([]Rule, error) {
+	var rules []Rule
+	ids := make(map[*Node]int)
+	var combinations []*Node
+	for _, node := range asonn.Nodes {
+		if _, ok := ids[node]; ok || node.Type != Combination {
+			continue
+		}
+		ids[node] = len(combinations)
+		combinations = append(combinations, node)
+	}
+	for id, node := range combinations {
+		rule := Rule{ID: id, Class: getClassOfObject(node), Parent: -1}
+		rule.Seeds, rule.Weeds = node.countSeedsAndWeeds()
+		for _, connection := range node.Connections {
+			if connection.Node.Type != Range {
+				continue
+			}
+			condition, err := asonn.newCondition(connection)
+			if err != nil {
+				return nil, err
+			}
+			rule.Conditions = append(rule.Conditions, condition)
+		}
+		rules = append(rules, rule)
+	}
+	for parentID, node := range combinations {
+		for _, connection := range node.Connections {
+			childID, ok := ids[connection.Node]
+			if ok && !areConnected(connection.Node, node) {
+				rules[childID].Parent = parentID
+			}
+		}
+	}
+	return rules, nil
+}
+
+func (asonn *Asonn) newCondition(connection Connection) (Condition, error) {
+	featureNode, err := getFeatureConnection(connection.Node)
+	if err != nil {
+		return Condition{}, err
+	}
+	if categories, ok := connection.Node.Value.(Categories); ok {
+		return Condition{Feature: fmt.Sprint(featureNode.Value), Categories: categories, Weight: connection.Weight}, nil
+	}
+	valRange, ok := connection.Node.Value.([2]interface{})
+	if !ok {
+		return Condition{}, fmt.Errorf("Range %v is not reduced", connection.Node.Value)
+	}
+	minVal, err := convertToFloat64(valRange[0])
+	if err != nil {
+		return Condition{}, err
+	}
+	maxVal, err := convertToFloat64(valRange[1])
+	if err != nil {
+		return Condition{}, err
+	}
+	condition := Condition{Feature: fmt.Sprint(featureNode.Value), Min: minVal, Max: maxVal, Weight: connection.Weight}
+	if column, ok := asonn.graph().columns[featureNode]; ok && column.Type == OrdinalColumn {
+		condition.Categories = column.ordinalCategories(minVal, maxVal)
+	}
+	return condition, nil
+}
+
+func (rule Rule) String() string {
+	var conditions []string
+	for _, condition := range rule.Conditions {
+		if condition.Categories != nil {
+			conditions = append(conditions, fmt.Sprintf("%s in %v", condition.Feature, Categories(condition.Categories)))
+			continue
+		}
+		conditions = append(conditions, fmt.Sprintf("%s in [%v, %v]", condition.Feature, condition.Min, condition.Max))
+	}
+	return "IF " + strings.Join(conditions, " AND ") + " THEN " + rule.Class
+}
+
+// WriteRules renders rules as plain text, a Markdown table or JSON.
+func WriteRules(w io.Writer, rules []Rule, format RuleFormat) error {
+	switch format {
+	case RulesText:
+		return writeRulesText(w, rules)
+	case RulesMarkdown:
+		return writeRulesMarkdown(w, rules)
+	case RulesJSON:
+		return writeRulesJSON(w, rules)
+	default:
+		return fmt.Errorf("Unknown rule format %d", format)
+	}
+}
+
+func writeRulesText(w io.Writer, rules []Rule) error {
+	var builder strings.Builder
+	for _, rule := range rules {
+		fmt.Fprintf(&builder, "%d: %s\n", rule.ID, rule)
+		fmt.Fprintf(&builder, "\tseeds=%d weeds=%d", rule.Seeds, rule.Weeds)
+		if rule.Parent >= 0 {
+			fmt.Fprintf(&builder, " refines=%d", rule.Parent)
+		}
+		builder.WriteString("\n")
+		for _, condition := range rule.Conditions {
+			fmt.Fprintf(&builder, "\t%s weight=%s\n", condition.Feature, formatWeight(condition.Weight))
+		}
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func writeRulesMarkdown(w io.Writer, rules []Rule) error {
+	var builder strings.Builder
+	builder.WriteString("| ID | Rule | Weights | Seeds | Weeds | Refines |\n")
+	builder.WriteString("|---|---|---|---|---|---|\n")
+	for _, rule := range rules {
+		var weights []string
+		for _, condition := range rule.Conditions {
+			weights = append(weights, condition.Feature+"="+formatWeight(condition.Weight))
+		}
+		parent := ""
+		if rule.Parent >= 0 {
+			parent = fmt.Sprint(rule.Parent)
+		}
+		text := strings.ReplaceAll(rule.String(), "|", "\\|")
+		fmt.Fprintf(&builder, "| %d | %s | %s | %d | %d | %s |\n", rule.ID, text, strings.Join(weights, ", "), rule.Seeds, rule.Weeds, parent)
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+type jsonRule struct {
+	ID         int             `json:"id"`
+	Class      string          `json:"class"`
+	Conditions []jsonCondition `json:"conditions"`
+	Seeds      int             `json:"seeds"`
+	Weeds      int             `json:"weeds"`
+	Parent     *int            `json:"parent"`
+}
+
+type jsonCondition struct {
+	Feature    string   `json:"feature"`
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Weight     *float64 `json:"weight"`
+}
+
+func writeRulesJSON(w io.Writer, rules []Rule) error {
+	document := []jsonRule{}
+	for _, rule := range rules {
+		jsonRule := jsonRule{ID: rule.ID, Class: rule.Class, Conditions: []jsonCondition{}, Seeds: rule.Seeds, Weeds: rule.Weeds}
+		if rule.Parent >= 0 {
+			parent := rule.Parent
+			jsonRule.Parent = &parent
+		}
+		for _, condition := range rule.Conditions {
+			jsonCondition := jsonCondition{Feature: condition.Feature, Categories: condition.Categories, Weight: finiteOrNil(condition.Weight)}
+			if condition.Categories == nil {
+				min, max := condition.Min, condition.Max
+				jsonCondition.Min, jsonCondition.Max = &min, &max
+			}
+			jsonRule.Conditions = append(jsonRule.Conditions, jsonCondition)
+		}
+		document = append(document, jsonRule)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
@@ -0,0 +1,93 @@
+package gasonn
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExtractRules(t *testing.T) {
+	asonn, err := Train(trainX, trainY, WithStrategy(SingleLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := asonn.ExtractRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) == 0 {
+		t.Fatalf("No rules extracted")
+	}
+	for _, rule := range rules {
+		if len(rule.Conditions) != len(trainX[0]) {
+			t.Errorf("Rule %d has %d conditions instead of %d", rule.ID, len(rule.Conditions), len(trainX[0]))
+		}
+		if rule.Seeds == 0 {
+			t.Errorf("Rule %d has no seeds", rule.ID)
+		}
+		if rule.Parent != -1 {
+			t.Errorf("Single layer rule %d refines rule %d", rule.ID, rule.Parent)
+		}
+		for _, condition := range rule.Conditions {
+			if condition.Min > condition.Max {
+				t.Errorf("Invalid condition %v", condition)
+			}
+		}
+	}
+	if !strings.HasPrefix(rules[0].String(), "IF a in [") {
+		t.Errorf("Unexpected rule %s", rules[0])
+	}
+}
+
+func TestExtractRulesMultiLayer(t *testing.T) {
+	asonn, err := Train(overlappingX, overlappingY, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := asonn.ExtractRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refining := 0
+	for _, rule := range rules {
+		if rule.Parent >= 0 {
+			refining++
+			if rules[rule.Parent].Class == rule.Class {
+				t.Errorf("Rule %d refines rule of the same class", rule.ID)
+			}
+		}
+	}
+	if refining == 0 {
+		t.Errorf("No refining rules extracted")
+	}
+}
+
+func TestWriteRules(t *testing.T) {
+	asonn, err := Train(trainX, trainY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := asonn.ExtractRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err := WriteRules(&buffer, rules, RulesMarkdown); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buffer.String(), "\n"); lines != len(rules)+2 {
+		t.Errorf("Markdown table has %d lines instead of %d", lines, len(rules)+2)
+	}
+	buffer.Reset()
+	if err := WriteRules(&buffer, rules, RulesJSON); err != nil {
+		t.Fatal(err)
+	}
+	var document []jsonRule
+	if err := json.Unmarshal(buffer.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	if len(document) != len(rules) {
+		t.Errorf("Wrote %d rules instead of %d", len(document), len(rules))
+	}
+}
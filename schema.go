@@ -0,0 +1,288 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+var (
+	ErrInvalidSchema = errors.New("Invalid schema")
+	ErrInvalidValue  = errors.New("Value doesn't match column type")
+)
+
+// ColumnType tells how the cells of a column are read.
+type ColumnType int
+
+const (
+	// NumericColumn holds float64 values.
+	NumericColumn ColumnType = iota
+	// IntegerColumn holds integers, kept as float64 values so they are linked
+	// and ranged like numeric values.
+	IntegerColumn
+	// OrdinalColumn holds categories with an order, read as their position
+	// in Column.Order so ranges span neighbouring categories.
+	OrdinalColumn
+	// CategoricalColumn holds unordered categories.
+	CategoricalColumn
+)
+
+func (columnType ColumnType) String() string {
+	switch columnType {
+	case NumericColumn:
+		return "numeric"
+	case IntegerColumn:
+		return "integer"
+	case OrdinalColumn:
+		return "ordinal"
+	case CategoricalColumn:
+		return "categorical"
+	default:
+		return fmt.Sprintf("ColumnType(%d)", int(columnType))
+	}
+}
+
+// Column is the type of a feature.
+type Column struct {
+	Name string
+	Type ColumnType
+	// Order lists the categories of an ordinal column from lowest to highest.
+	Order []string
+	// Missing is the policy for empty and "?" cells.
+	Missing MissingPolicy
+	// Fill is the cell imputation policies fill in, computed during training
+	// when empty.
+	Fill string
+}
+
+// Schema holds the column types of a model, in the order of the features.
+type Schema []Column
+
+// WithSchema sets the type of the columns schema names. Columns it doesn't
+// name are inferred from the training data.
+func WithSchema(schema Schema) Option {
+	return func(o *options) {
+		o.schema = schema
+	}
+}
+
+// InferSchema reads the type of every column of x from its labelled rows. A
+// column is integer when all its values are integers, numeric when all are
+// numbers and categorical otherwise. Missing cells are ignored.
+func InferSchema(x [][]string, y []string) Schema {
+	schema := make(Schema, len(x[0]))
+	for j, name := range x[0] {
+		schema[j] = Column{Name: name, Type: IntegerColumn}
+		for i := 1; i < len(x); i++ {
+			if y[i] == "" || isMissing(x[i][j]) {
+				continue
+			}
+			switch convertToCorrectType(x[i][j]).(type) {
+			case float64:
+				if schema[j].Type == IntegerColumn {
+					schema[j].Type = NumericColumn
+				}
+			case string:
+				schema[j].Type = CategoricalColumn
+			}
+			if schema[j].Type == CategoricalColumn {
+				break
+			}
+		}
+	}
+	return schema
+}
+
+// trainingSchema infers the columns of x that explicit doesn't name, with the
+// missing value policy missing, and computes imputed values.
+func trainingSchema(x [][]string, y []string, explicit Schema, missing MissingPolicy) (Schema, error) {
+	if err := explicit.validate(); err != nil {
+		return nil, err
+	}
+	schema := InferSchema(x, y)
+	for j := range schema {
+		schema[j].Missing = missing
+	}
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	given := make(map[string]int)
+	for _, column := range explicit {
+		j := nthIndexOf(x[0], column.Name, given[column.Name])
+		if j < 0 {
+			return nil, fmt.Errorf("%w: no column %s in data", ErrInvalidSchema, column.Name)
+		}
+		given[column.Name]++
+		schema[j] = column
+	}
+	for j := range schema {
+		if schema[j].Fill != "" || schema[j].Missing == SkipMissing || schema[j].Missing == MissingAsCategory {
+			continue
+		}
+		var cells []string
+		for i := 1; i < len(x); i++ {
+			if y[i] != "" && !isMissing(x[i][j]) {
+				cells = append(cells, x[i][j])
+			}
+		}
+		schema[j].Fill = schema[j].imputation(cells)
+	}
+	return schema, nil
+}
+
+func (schema Schema) validate() error {
+	for _, column := range schema {
+		switch column.Type {
+		case NumericColumn, IntegerColumn, CategoricalColumn:
+		case OrdinalColumn:
+			if len(column.Order) == 0 {
+				return fmt.Errorf("%w: ordinal column %s has no order", ErrInvalidSchema, column.Name)
+			}
+			if len(column.Order) != len(newSet(column.Order)) {
+				return fmt.Errorf("%w: ordinal column %s repeats a category", ErrInvalidSchema, column.Name)
+			}
+		default:
+			return fmt.Errorf("%w: column %s has type %v", ErrInvalidSchema, column.Name, column.Type)
+		}
+		if column.Missing < SkipMissing || column.Missing > MissingAsCategory {
+			return fmt.Errorf("%w: column %s has missing value policy %d", ErrInvalidSchema, column.Name, column.Missing)
+		}
+	}
+	return nil
+}
+
+// column returns the column called name.
+func (schema Schema) column(name string) (Column, bool) {
+	return schema.nthColumn(name, 0)
+}
+
+// nthColumn returns the column called name that follows n others of that
+// name. A header naming several columns alike has a column for each.
+func (schema Schema) nthColumn(name string, n int) (Column, bool) {
+	for _, column := range schema {
+		if column.Name != name {
+			continue
+		}
+		if n == 0 {
+			return column, true
+		}
+		n--
+	}
+	return Column{}, false
+}
+
+// columnsOf returns the column of every name of header schema has, by
+// position, matching repeated names in order.
+func (schema Schema) columnsOf(header []string) map[int]Column {
+	columns := make(map[int]Column)
+	seen := make(map[string]int)
+	for j, name := range header {
+		if column, ok := schema.nthColumn(name, seen[name]); ok {
+			columns[j] = column
+		}
+		seen[name]++
+	}
+	return columns
+}
+
+// parse reads a cell of the column as a node value.
+func (column Column) parse(cell string) (interface{}, error) {
+	if column.Type == CategoricalColumn && column.Missing == MissingAsCategory && isMissing(cell) {
+		return MissingCategory, nil
+	}
+	switch column.Type {
+	case NumericColumn:
+		if value, err := strconv.ParseFloat(cell, 64); err == nil {
+			return value, nil
+		}
+	case IntegerColumn:
+		if value, err := strconv.Atoi(cell); err == nil {
+			return float64(value), nil
+		}
+	case OrdinalColumn:
+		if position := indexOf(column.Order, cell); position >= 0 {
+			return float64(position), nil
+		}
+	case CategoricalColumn:
+		return cell, nil
+	}
+	return nil, fmt.Errorf("%w: %q in %v column %s", ErrInvalidValue, cell, column.Type, column.Name)
+}
+
+// format writes a node value of the column as a cell parse reads back.
+func (column Column) format(value interface{}) string {
+	if number, ok := value.(float64); ok && column.Type == IntegerColumn && number == math.Trunc(number) {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	if position, ok := value.(float64); ok && column.Type == OrdinalColumn {
+		if i := int(position); float64(i) == position && i >= 0 && i < len(column.Order) {
+			return column.Order[i]
+		}
+	}
+	return formatSampleValue(value)
+}
+
+// ordinalCategories returns the categories of an ordinal column between the
+// positions min and max.
+func (column Column) ordinalCategories(min, max float64) []string {
+	first := int(math.Max(0, math.Ceil(min)))
+	last := int(math.Min(float64(len(column.Order)-1), math.Floor(max)))
+	if first > last {
+		return []string{}
+	}
+	return append([]string(nil), column.Order[first:last+1]...)
+}
+
+// checkCells reports the first cell of test that its column of schema can't
+// read.
+func (schema Schema) checkCells(test [][]string) error {
+	columns := schema.columnsOf(test[0])
+	for j := range test[0] {
+		column, ok := columns[j]
+		if !ok {
+			continue
+		}
+		for i, row := range test[1:] {
+			if column.skips(row[j]) {
+				continue
+			}
+			if _, err := column.parse(row[j]); err != nil {
+				return fmt.Errorf("%w in row %d", err, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+func indexOf(values []string, value string) int {
+	for i := range values {
+		if values[i] == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// nthIndexOf returns the index of the value of values that follows n others
+// equal to value, -1 when there is none.
+func nthIndexOf(values []string, value string, n int) int {
+	for i := range values {
+		if values[i] != value {
+			continue
+		}
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return -1
+}
+
+func newSet(values []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
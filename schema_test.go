@@ -0,0 +1,210 @@
+package gasonn
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var schemaX = [][]string{
+	{"count", "length", "grade", "colour"},
+	{"1", "1.5", "low", "red"},
+	{"2", "2", "low", "red"},
+	{"1", "1.0", "medium", "blue"},
+	{"7", "7.5", "high", "green"},
+	{"8", "8", "high", "green"},
+	{"x", "y", "z", "w"},
+}
+
+var schemaY = []string{"target", "p", "p", "p", "n", "n", ""}
+
+var gradeColumn = Column{Name: "grade", Type: OrdinalColumn, Order: []string{"low", "medium", "high"}}
+
+func TestInferSchema(t *testing.T) {
+	expected := Schema{
+		{Name: "count", Type: IntegerColumn},
+		{Name: "length", Type: NumericColumn},
+		{Name: "grade", Type: CategoricalColumn},
+		{Name: "colour", Type: CategoricalColumn},
+	}
+	if schema := InferSchema(schemaX, schemaY); !reflect.DeepEqual(schema, expected) {
+		t.Errorf("Inferred %v instead of %v", schema, expected)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	x, y := schemaX[:6], schemaY[:6]
+	asonn, err := Train(x, y, WithStrategy(MultiLayer), WithSchema(Schema{gradeColumn}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph := asonn.graph()
+	for _, feature := range graph.nodes(Feature) {
+		for _, valueNode := range graph.featureValues[feature] {
+			if feature.Value == "length" || feature.Value == "grade" {
+				if _, ok := valueNode.Value.(float64); !ok {
+					t.Errorf("Value %v of %v is %T", valueNode.Value, feature.Value, valueNode.Value)
+				}
+			}
+		}
+	}
+	if got := accuracy(t, asonn, x, y); got != 1 {
+		t.Errorf("Accuracy %f on the training data", got)
+	}
+	rules, err := asonn.ExtractRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range rules {
+		for _, condition := range rule.Conditions {
+			if condition.Feature == "grade" && len(condition.Categories) == 0 {
+				t.Errorf("Ordinal condition %v has no categories", condition)
+			}
+		}
+	}
+	var buffer bytes.Buffer
+	if err := asonn.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Schema, asonn.Schema) {
+		t.Errorf("Loaded schema %v instead of %v", loaded.Schema, asonn.Schema)
+	}
+	if samples, _ := loaded.Samples(); samples[1][2] != "low" {
+		t.Errorf("Ordinal sample value %q instead of low", samples[1][2])
+	}
+	if _, err := loaded.Classify([][]string{x[0], {"1", "1.5", "lowest", "red"}}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Got error %v instead of %v", err, ErrInvalidValue)
+	}
+	if err := loaded.Learn([]string{"2", "1.5", "medium", "blue"}, "p"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIntegerColumn(t *testing.T) {
+	x := [][]string{{"count"}, {"1"}, {"2"}, {"3"}, {"10"}, {"11"}, {"12"}}
+	y := []string{"target", "p", "p", "p", "n", "n", "n"}
+	asonn, err := Train(x, y, WithStrategy(MultiLayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asonn.Schema[0].Type != IntegerColumn {
+		t.Fatalf("Column read as %v", asonn.Schema[0].Type)
+	}
+	graph := asonn.graph()
+	for _, valueNode := range graph.featureValues[graph.nodes(Feature)[0]] {
+		linked := false
+		for _, connection := range valueNode.Connections {
+			linked = linked || connection.Node.Type == Value
+		}
+		if !linked {
+			t.Errorf("Value %v has no ASIM link", valueNode.Value)
+		}
+	}
+	generalising := false
+	for _, rangeNode := range graph.nodes(Range) {
+		if min, max, _ := rangeBounds(rangeNode); min == 1 && max == 3 {
+			generalising = true
+		}
+	}
+	if !generalising {
+		t.Errorf("No range spans 1 to 3")
+	}
+	labels, err := asonn.Classify([][]string{x[0], {"4"}, {"9"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels[0].Label != "p" || labels[1].Label != "n" {
+		t.Errorf("Classified 4 and 9 as %s and %s instead of p and n", labels[0].Label, labels[1].Label)
+	}
+	if samples, _ := asonn.Samples(); samples[1][0] != "1" {
+		t.Errorf("Integer sample value %q instead of 1", samples[1][0])
+	}
+	for _, valueNode := range graph.nodes(Value) {
+		valueNode.Value = int(valueNode.Value.(float64))
+	}
+	var buffer bytes.Buffer
+	if err := asonn.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, valueNode := range loaded.graph().nodes(Value) {
+		if _, ok := valueNode.Value.(float64); !ok {
+			t.Errorf("Saved int value %v loaded as %T", valueNode.Value, valueNode.Value)
+		}
+	}
+}
+
+func TestRepeatedColumns(t *testing.T) {
+	x := [][]string{
+		{"size", "size", "colour"},
+		{"1", "small", "red"},
+		{"2", "small", "red"},
+		{"1", "large", "blue"},
+		{"7", "large", "green"},
+		{"8", "small", "green"},
+	}
+	y := []string{"target", "p", "p", "p", "n", "n"}
+	expected := Schema{
+		{Name: "size", Type: IntegerColumn},
+		{Name: "size", Type: CategoricalColumn},
+		{Name: "colour", Type: CategoricalColumn},
+	}
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		asonn, err := Train(x, y, WithStrategy(strategy), WithSchema(Schema{{Name: "size", Type: IntegerColumn}, {Name: "size", Type: CategoricalColumn}}))
+		if err != nil {
+			t.Fatalf("Strategy %d: %v", strategy, err)
+		}
+		if !reflect.DeepEqual(asonn.Schema, expected) {
+			t.Errorf("Strategy %d: schema %v instead of %v", strategy, asonn.Schema, expected)
+		}
+		graph := asonn.graph()
+		features := graph.features["size"]
+		if len(features) != 2 || graph.categorical[features[0]] || !graph.categorical[features[1]] {
+			t.Errorf("Strategy %d: columns of size are not read in order", strategy)
+		}
+		var buffer bytes.Buffer
+		if err := asonn.Save(&buffer); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(&buffer)
+		if err != nil {
+			t.Fatalf("Strategy %d: %v", strategy, err)
+		}
+		if got := accuracy(t, loaded, x, y); got != 1 {
+			t.Errorf("Strategy %d: accuracy %f on the training data", strategy, got)
+		}
+	}
+}
+
+type schemaErrorTestData struct {
+	name   string
+	schema Schema
+	err    error
+}
+
+var schemaErrorTests = []schemaErrorTestData{
+	{"unknown column", Schema{{Name: "size", Type: NumericColumn}}, ErrInvalidSchema},
+	{"repeated column", Schema{{Name: "count"}, {Name: "count"}}, ErrInvalidSchema},
+	{"no order", Schema{{Name: "grade", Type: OrdinalColumn}}, ErrInvalidSchema},
+	{"repeated category", Schema{{Name: "grade", Type: OrdinalColumn, Order: []string{"low", "low"}}}, ErrInvalidSchema},
+	{"unknown type", Schema{{Name: "grade", Type: ColumnType(9)}}, ErrInvalidSchema},
+	{"missing category", Schema{{Name: "grade", Type: OrdinalColumn, Order: []string{"low", "high"}}}, ErrInvalidValue},
+	{"non numeric", Schema{{Name: "colour", Type: NumericColumn}}, ErrInvalidValue},
+}
+
+func TestSchemaErrors(t *testing.T) {
+	for _, testData := range schemaErrorTests {
+		_, err := Train(schemaX, schemaY, WithSchema(testData.schema))
+		if !errors.Is(err, testData.err) {
+			t.Errorf("%s: got error %v instead of %v", testData.name, err, testData.err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package gasonn
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrEmptyData       = errors.New("No training data")
+	ErrLengthMismatch  = errors.New("Number of labels doesn't match number of rows")
+	ErrRaggedRow       = errors.New("Row length doesn't match header length")
+	ErrNoClasses       = errors.New("No labelled rows")
+	ErrNonNumericRange = errors.New("Range over non numeric values")
+)
+
+// Strategy selects how combination nodes are built during training.
+type Strategy int
+
+const (
+	// SingleLayer expands combinations around the most out-correlated objects (BuildAsonn).
+	SingleLayer Strategy = iota
+	// MultiLayer builds one combination per class and refines it with inhibitory sublayers (BuildNewAsonn).
+	MultiLayer
+)
+
+type options struct {
+	strategy Strategy
+	config   Config
+	schema   Schema
+	missing  MissingPolicy
+	// intervals is the number of target intervals of TrainRegression.
+	intervals int
+}
+
+// Option configures Train.
+type Option func(*options)
+
+// WithStrategy selects the combination construction strategy, SingleLayer by default.
+func WithStrategy(strategy Strategy) Option {
+	return func(o *options) {
+		o.strategy = strategy
+	}
+}
+
+// Train builds an Asonn from x, whose first row holds feature names, and y,
+// whose first element is the target name. Rows with an empty label are skipped.
+func Train(x [][]string, y []string, opts ...Option) (*Asonn, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(x, y); err != nil {
+		return nil, err
+	}
+	schema, err := trainingSchema(x, y, o.schema, o.missing)
+	if err != nil {
+		return nil, err
+	}
+	x = schema.fillMissing(x, x)
+	asonn := &Asonn{Config: &o.config, Schema: schema}
+	classNodes, err := asonn.addObjects(x, y)
+	if err != nil {
+		return nil, err
+	}
+	if err := asonn.build(o.strategy, classNodes); err != nil {
+		return nil, err
+	}
+	return asonn, nil
+}
+
+func newOptions(opts []Option) (options, error) {
+	o := options{strategy: SingleLayer, config: DefaultConfig()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.strategy != SingleLayer && o.strategy != MultiLayer {
+		return o, fmt.Errorf("Unknown strategy %d", o.strategy)
+	}
+	return o, o.config.validate()
+}
+
+// build connects the objects and adds combinations with strategy.
+func (asonn *Asonn) build(strategy Strategy, classNodes []*Node) error {
+	asonn.addAsimAndAdefConnections()
+	switch strategy {
+	case SingleLayer:
+		if err := asonn.addCombinations(); err != nil {
+			return err
+		}
+		asonn.updateRangeToCombinationConnectionWeights()
+		asonn.removeValueAndObjectNodes()
+	case MultiLayer:
+		if err := asonn.addCombinationLayers(classNodes); err != nil {
+			return err
+		}
+	}
+	asonn.Reindex()
+	return nil
+}
+
+func validate(x [][]string, y []string) error {
+	if len(x) < 2 || len(x[0]) == 0 {
+		return ErrEmptyData
+	}
+	if len(y) != len(x) {
+		return fmt.Errorf("%w: %d rows, %d labels", ErrLengthMismatch, len(x), len(y))
+	}
+	labelled := 0
+	for i := 1; i < len(x); i++ {
+		if y[i] == "" {
+			continue
+		}
+		if len(x[i]) != len(x[0]) {
+			return fmt.Errorf("%w: row %d has %d values, header has %d", ErrRaggedRow, i, len(x[i]), len(x[0]))
+		}
+		labelled++
+	}
+	if labelled == 0 {
+		return ErrNoClasses
+	}
+	return nil
+}
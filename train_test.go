@@ -0,0 +1,62 @@
+package gasonn
+
+import (
+	"errors"
+	"testing"
+)
+
+var trainX = [][]string{
+	{"a", "b"},
+	{"1.0", "2.5"},
+	{"1.2", "2.7"},
+	{"3.1", "0.5"},
+	{"3.3", "0.7"},
+	{"1.1", "2.6"},
+	{"3.0", "0.4"},
+}
+
+var trainY = []string{"target", "p", "p", "n", "n", "p", "n"}
+
+func TestTrain(t *testing.T) {
+	for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+		asonn, err := Train(trainX, trainY, WithStrategy(strategy))
+		if err != nil {
+			t.Fatalf("Strategy %d: %v", strategy, err)
+		}
+		combinations := 0
+		for _, node := range asonn.Nodes {
+			if node.Type == Combination {
+				combinations++
+			}
+		}
+		if combinations < 2 {
+			t.Errorf("Strategy %d: created %d combinations instead of at least 2", strategy, combinations)
+		}
+	}
+}
+
+type trainErrorTestData struct {
+	name string
+	x    [][]string
+	y    []string
+	err  error
+}
+
+var trainErrorTests = []trainErrorTestData{
+	{"empty", nil, nil, ErrEmptyData},
+	{"header only", [][]string{{"a"}}, []string{"target"}, ErrEmptyData},
+	{"short y", [][]string{{"a"}, {"1"}, {"2"}}, []string{"target", "p"}, ErrLengthMismatch},
+	{"ragged", [][]string{{"a", "b"}, {"1", "2"}, {"3"}}, []string{"target", "p", "n"}, ErrRaggedRow},
+	{"no classes", [][]string{{"a"}, {"1"}, {"2"}}, []string{"target", "", ""}, ErrNoClasses},
+}
+
+func TestTrainErrors(t *testing.T) {
+	for _, testData := range trainErrorTests {
+		for _, strategy := range []Strategy{SingleLayer, MultiLayer} {
+			_, err := Train(testData.x, testData.y, WithStrategy(strategy))
+			if !errors.Is(err, testData.err) {
+				t.Errorf("%s: got error %v instead of %v", testData.name, err, testData.err)
+			}
+		}
+	}
+}